@@ -0,0 +1,154 @@
+// Package api implements the on-chain checkpoint oracle management API and common data types.
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	"github.com/oasisprotocol/oasis-core/go/common/pubsub"
+	"github.com/oasisprotocol/oasis-core/go/consensus/api/transaction"
+)
+
+const (
+	// ModuleName is a unique module name for the checkpoint oracle module.
+	ModuleName = "checkpointoracle"
+
+	// CheckpointNumKept is the default number of checkpoints kept per runtime.
+	CheckpointNumKept = 16
+)
+
+var (
+	// ErrInvalidArgument is the error returned on malformed arguments.
+	ErrInvalidArgument = errors.New(ModuleName, 1, "checkpointoracle: invalid argument")
+
+	// ErrNoSuchCheckpoint is the error returned when no checkpoint has been published for a runtime.
+	ErrNoSuchCheckpoint = errors.New(ModuleName, 2, "checkpointoracle: no such checkpoint")
+
+	// ErrNotEnoughSignatures is the error returned when a submitted checkpoint does not carry the
+	// required M-of-N signer threshold.
+	ErrNotEnoughSignatures = errors.New(ModuleName, 3, "checkpointoracle: not enough signatures")
+
+	// ErrSignerNotRegistered is the error returned when a checkpoint is signed by a key that is not
+	// a registered signer.
+	ErrSignerNotRegistered = errors.New(ModuleName, 4, "checkpointoracle: signer not registered")
+
+	// MethodRegisterSigners is the method name for registering the M-of-N signer set.
+	MethodRegisterSigners = transaction.NewMethodName(ModuleName, "RegisterSigners", SignerSet{})
+
+	// MethodSubmitCheckpoint is the method name for submitting a signed checkpoint.
+	MethodSubmitCheckpoint = transaction.NewMethodName(ModuleName, "SubmitCheckpoint", SignedCheckpoint{})
+
+	// Methods is the list of all methods supported by the checkpoint oracle backend.
+	Methods = []transaction.MethodName{
+		MethodRegisterSigners,
+		MethodSubmitCheckpoint,
+	}
+
+	// checkpointSignatureContext is the context used to sign checkpoint tuples.
+	checkpointSignatureContext = signature.NewContext("oasis-core/checkpointoracle: checkpoint")
+)
+
+const (
+	// GasOpRegisterSigners is the gas operation identifier for registering signers.
+	GasOpRegisterSigners transaction.Op = "register_signers"
+	// GasOpSubmitCheckpoint is the gas operation identifier for submitting a checkpoint.
+	GasOpSubmitCheckpoint transaction.Op = "submit_checkpoint"
+)
+
+// DefaultGasCosts are the "default" gas costs for operations.
+var DefaultGasCosts = transaction.Costs{
+	GasOpRegisterSigners:  1000,
+	GasOpSubmitCheckpoint: 1000,
+}
+
+// Checkpoint is a signed tuple attesting to a runtime's storage state at a given round.
+type Checkpoint struct {
+	RuntimeID   common.Namespace `json:"runtime_id"`
+	Round       uint64           `json:"round"`
+	StorageRoot hash.Hash        `json:"storage_root"`
+	IORoot      hash.Hash        `json:"io_root"`
+}
+
+// SignerSet is the M-of-N set of signers authorized to publish checkpoints for a runtime.
+type SignerSet struct {
+	RuntimeID common.Namespace      `json:"runtime_id"`
+	Threshold uint32                `json:"threshold"`
+	Signers   []signature.PublicKey `json:"signers"`
+}
+
+// SignedCheckpoint is a Checkpoint accompanied by at least Threshold signatures
+// over its canonical CBOR encoding, one per distinct registered signer.
+type SignedCheckpoint struct {
+	Checkpoint Checkpoint               `json:"checkpoint"`
+	Signatures []signature.RawSignature `json:"signatures"`
+	SignerIDs  []signature.PublicKey    `json:"signer_ids"`
+}
+
+// Verify checks that SignedCheckpoint carries at least set.Threshold valid, distinct
+// signatures from set.Signers over the checkpoint.
+func (sc *SignedCheckpoint) Verify(set *SignerSet) error {
+	if len(sc.Signatures) != len(sc.SignerIDs) {
+		return fmt.Errorf("checkpointoracle: signature/signer count mismatch")
+	}
+
+	registered := make(map[signature.PublicKey]bool)
+	for _, pk := range set.Signers {
+		registered[pk] = true
+	}
+
+	raw := cbor.Marshal(sc.Checkpoint)
+	seen := make(map[signature.PublicKey]bool)
+	var valid uint32
+	for i, pk := range sc.SignerIDs {
+		if !registered[pk] {
+			return ErrSignerNotRegistered
+		}
+		if seen[pk] {
+			continue
+		}
+		if !pk.Verify(checkpointSignatureContext, raw, sc.Signatures[i][:]) {
+			return fmt.Errorf("checkpointoracle: invalid signature from %s", pk)
+		}
+		seen[pk] = true
+		valid++
+	}
+
+	if valid < set.Threshold {
+		return ErrNotEnoughSignatures
+	}
+	return nil
+}
+
+// NewRegisterSignersTx creates a new register signers transaction.
+func NewRegisterSignersTx(nonce uint64, fee *transaction.Fee, set *SignerSet) *transaction.Transaction {
+	return transaction.NewTransaction(nonce, fee, MethodRegisterSigners, set)
+}
+
+// NewSubmitCheckpointTx creates a new submit checkpoint transaction.
+func NewSubmitCheckpointTx(nonce uint64, fee *transaction.Fee, sc *SignedCheckpoint) *transaction.Transaction {
+	return transaction.NewTransaction(nonce, fee, MethodSubmitCheckpoint, sc)
+}
+
+// Backend is a checkpoint oracle management implementation.
+type Backend interface {
+	// GetLatestCheckpoint returns the latest accepted checkpoint for a runtime.
+	GetLatestCheckpoint(ctx context.Context, runtimeID common.Namespace) (*Checkpoint, error)
+
+	// WatchCheckpoints returns a channel that produces a stream of accepted checkpoints.
+	WatchCheckpoints() (<-chan *Checkpoint, *pubsub.Subscription)
+}
+
+// CheckpointAcceptedEvent is the checkpoint oracle checkpoint acceptance event.
+type CheckpointAcceptedEvent struct {
+	Checkpoint *Checkpoint
+}
+
+// EventKind returns a string representation of this event's kind.
+func (ev *CheckpointAcceptedEvent) EventKind() string {
+	return "checkpoint"
+}
@@ -8,11 +8,13 @@ import (
 	"github.com/oasislabs/ekiden/go/common/crypto/signature"
 	pbClient "github.com/oasislabs/ekiden/go/grpc/client"
 	pbEnRPC "github.com/oasislabs/ekiden/go/grpc/enclaverpc"
+	pbKeyManager "github.com/oasislabs/ekiden/go/grpc/keymanager"
 )
 
 var (
-	_ pbClient.RuntimeServer   = (*grpcServer)(nil)
-	_ pbEnRPC.EnclaveRpcServer = (*grpcServer)(nil)
+	_ pbClient.RuntimeServer        = (*grpcServer)(nil)
+	_ pbEnRPC.EnclaveRpcServer      = (*grpcServer)(nil)
+	_ pbKeyManager.KeyManagerServer = (*grpcServer)(nil)
 )
 
 type grpcServer struct {
@@ -95,6 +97,118 @@ func (s *grpcServer) CallEnclave(ctx context.Context, req *pbEnRPC.CallEnclaveRe
 	return &pbEnRPC.CallEnclaveResponse{Payload: rsp}, nil
 }
 
+func (s *grpcServer) GetStatus(ctx context.Context, req *pbKeyManager.GetStatusRequest) (*pbKeyManager.GetStatusResponse, error) {
+	var id signature.PublicKey
+	if err := id.UnmarshalBinary(req.GetKmRuntimeId()); err != nil {
+		return nil, err
+	}
+
+	status, err := s.client.KeyManager().GetStatus(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbKeyManager.GetStatusResponse{Status: status.MarshalCBOR()}, nil
+}
+
+func (s *grpcServer) GetStatuses(ctx context.Context, req *pbKeyManager.GetStatusesRequest) (*pbKeyManager.GetStatusesResponse, error) {
+	statuses, err := s.client.KeyManager().GetStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &pbKeyManager.GetStatusesResponse{}
+	for _, status := range statuses {
+		rsp.Statuses = append(rsp.Statuses, status.MarshalCBOR())
+	}
+	return rsp, nil
+}
+
+func (s *grpcServer) WatchStatuses(req *pbKeyManager.WatchStatusesRequest, stream pbKeyManager.KeyManager_WatchStatusesServer) error {
+	ch, sub := s.client.KeyManager().WatchStatuses()
+	defer sub.Close()
+
+	for {
+		select {
+		case status, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(&pbKeyManager.WatchStatusesResponse{Status: status.MarshalCBOR()}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (s *grpcServer) GetEphemeralSecret(ctx context.Context, req *pbKeyManager.GetEphemeralSecretRequest) (*pbKeyManager.GetEphemeralSecretResponse, error) {
+	var id signature.PublicKey
+	if err := id.UnmarshalBinary(req.GetRuntimeId()); err != nil {
+		return nil, err
+	}
+
+	secret, err := s.client.KeyManager().GetEphemeralSecret(ctx, id, req.GetEpoch())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbKeyManager.GetEphemeralSecretResponse{Secret: secret.MarshalCBOR()}, nil
+}
+
+func (s *grpcServer) WatchEphemeralSecrets(req *pbKeyManager.WatchEphemeralSecretsRequest, stream pbKeyManager.KeyManager_WatchEphemeralSecretsServer) error {
+	ch, sub := s.client.KeyManager().WatchEphemeralSecrets()
+	defer sub.Close()
+
+	for {
+		select {
+		case secret, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(&pbKeyManager.WatchEphemeralSecretsResponse{Secret: secret.MarshalCBOR()}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (s *grpcServer) GetMasterSecret(ctx context.Context, req *pbKeyManager.GetMasterSecretRequest) (*pbKeyManager.GetMasterSecretResponse, error) {
+	var id signature.PublicKey
+	if err := id.UnmarshalBinary(req.GetRuntimeId()); err != nil {
+		return nil, err
+	}
+
+	secret, err := s.client.KeyManager().GetMasterSecret(ctx, id, req.GetGeneration())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbKeyManager.GetMasterSecretResponse{Secret: secret.MarshalCBOR()}, nil
+}
+
+func (s *grpcServer) WatchMasterSecrets(req *pbKeyManager.WatchMasterSecretsRequest, stream pbKeyManager.KeyManager_WatchMasterSecretsServer) error {
+	ch, sub := s.client.KeyManager().WatchMasterSecrets()
+	defer sub.Close()
+
+	for {
+		select {
+		case secret, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(&pbKeyManager.WatchMasterSecretsResponse{Secret: secret.MarshalCBOR()}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
 // NewGRPCServer creates and registers a new GRPC server for the client interface.
 func NewGRPCServer(srv *grpc.Server, client *Client) {
 	s := &grpcServer{
@@ -102,4 +216,5 @@ func NewGRPCServer(srv *grpc.Server, client *Client) {
 	}
 	pbClient.RegisterRuntimeServer(srv, s)
 	pbEnRPC.RegisterEnclaveRpcServer(srv, s)
+	pbKeyManager.RegisterKeyManagerServer(srv, s)
 }
\ No newline at end of file
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// CanonicalDigest returns the SHA-512/256 digest of a document's canonical
+// encoding, used to confirm that independently-assembled genesis documents
+// are byte-for-byte identical.
+func CanonicalDigest(doc *Document) ([sha512.Size256]byte, error) {
+	b, err := CanonicalMarshal(doc)
+	if err != nil {
+		return [sha512.Size256]byte{}, err
+	}
+	return sha512.Sum512_256(b), nil
+}
+
+// CanonicalMarshal produces a deterministic JSON encoding of a genesis
+// document: map entries (such as Registry.Nodes keyed by signature.MapKey,
+// Staking.Ledger, and RootHash.Blocks) are sorted by their marshaled key
+// bytes, and every time.Time value is normalized to RFC3339 UTC. Two
+// coordinators assembling the same genesis state get byte-identical output.
+func CanonicalMarshal(doc *Document) ([]byte, error) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("genesis: failed to marshal document: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var generic interface{}
+	if err = dec.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("genesis: failed to decode document: %w", err)
+	}
+
+	generic = canonicalizeValue(generic)
+
+	canonical, err := canonicalEncode(generic)
+	if err != nil {
+		return nil, fmt.Errorf("genesis: failed to re-encode document: %w", err)
+	}
+	return canonical, nil
+}
+
+// canonicalizeValue recursively normalizes any RFC3339 timestamp strings to
+// RFC3339 UTC. Map key ordering is handled by canonicalEncode.
+func canonicalizeValue(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, e := range vv {
+			vv[k] = canonicalizeValue(e)
+		}
+		return vv
+	case []interface{}:
+		for i, e := range vv {
+			vv[i] = canonicalizeValue(e)
+		}
+		return vv
+	case string:
+		if ts, err := time.Parse(time.RFC3339Nano, vv); err == nil {
+			return ts.UTC().Format(time.RFC3339Nano)
+		}
+		return vv
+	default:
+		return v
+	}
+}
+
+// canonicalEncode writes out v as JSON with object keys sorted
+// lexicographically, regardless of whether the underlying map key type
+// implements encoding.TextMarshaler in a way that already sorts.
+func canonicalEncode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeCanonical(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(vv))
+		for k := range vv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			kb, err := json.Marshal(k)
+			if err != nil {
+				return err
+			}
+			buf.Write(kb)
+			buf.WriteByte(':')
+			if err = encodeCanonical(buf, vv[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range vv {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeCanonical(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	default:
+		b, err := json.Marshal(vv)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		return nil
+	}
+}
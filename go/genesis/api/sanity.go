@@ -0,0 +1,170 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/oasislabs/oasis-core/go/common/crypto/signature"
+	"github.com/oasislabs/oasis-core/go/common/entity"
+	"github.com/oasislabs/oasis-core/go/common/node"
+	"github.com/oasislabs/oasis-core/go/common/quantity"
+	registry "github.com/oasislabs/oasis-core/go/registry/api"
+)
+
+// SanityCheck runs a battery of cross-section consistency checks against a
+// genesis document and reports every violation it finds, rather than
+// bailing out on the first one. The one exception is a registry entity that
+// fails to open: the check stops there and returns that error alone.
+func SanityCheck(doc *Document) error {
+	entities, err := sanityCheckEntities(doc)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	errs = append(errs, sanityCheckNodes(doc, entities)...)
+	errs = append(errs, sanityCheckRuntimes(doc, entities)...)
+	errs = append(errs, sanityCheckScheduler(doc, entities)...)
+	errs = append(errs, sanityCheckStaking(doc)...)
+	errs = append(errs, sanityCheckKeyManagerOperator(doc, entities)...)
+	errs = append(errs, sanityCheckRootHash(doc)...)
+	errs = append(errs, sanityCheckHaltEpoch(doc)...)
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("genesis: sanity check failed with %d violation(s):", len(errs))
+	for _, e := range errs {
+		msg += "\n  - " + e.Error()
+	}
+	return errors.New(msg)
+}
+
+func sanityCheckEntities(doc *Document) (map[signature.MapKey]*entity.Entity, error) {
+	entities := make(map[signature.MapKey]*entity.Entity)
+	for _, se := range doc.Registry.Entities {
+		var ent entity.Entity
+		if err := se.Open(registry.RegisterGenesisEntitySignatureContext, &ent); err != nil {
+			return nil, fmt.Errorf("genesis: failed to open entity: %w", err)
+		}
+		entities[ent.ID.ToMapKey()] = &ent
+	}
+	return entities, nil
+}
+
+func sanityCheckNodes(doc *Document, entities map[signature.MapKey]*entity.Entity) []error {
+	var errs []error
+	for _, sn := range doc.Registry.Nodes {
+		var n node.Node
+		if err := sn.Open(registry.RegisterGenesisNodeSignatureContext, &n); err != nil {
+			errs = append(errs, fmt.Errorf("failed to open node: %w", err))
+			continue
+		}
+		if _, ok := entities[n.EntityID.ToMapKey()]; !ok {
+			errs = append(errs, fmt.Errorf("node %s references unknown entity %s", n.ID, n.EntityID))
+		}
+	}
+	return errs
+}
+
+func sanityCheckRuntimes(doc *Document, entities map[signature.MapKey]*entity.Entity) []error {
+	kmStatuses := make(map[signature.MapKey]bool)
+	for _, st := range doc.KeyManager.Statuses {
+		kmStatuses[st.ID.ToMapKey()] = true
+	}
+
+	var errs []error
+	for _, sr := range doc.Registry.Runtimes {
+		var rt registry.Runtime
+		if err := sr.Open(registry.RegisterGenesisRuntimeSignatureContext, &rt); err != nil {
+			errs = append(errs, fmt.Errorf("failed to open runtime: %w", err))
+			continue
+		}
+		if _, ok := entities[rt.EntityID.ToMapKey()]; !ok {
+			errs = append(errs, fmt.Errorf("runtime %s references unknown entity %s", rt.ID, rt.EntityID))
+		}
+		if rt.Kind == registry.KindCompute && rt.KeyManager != nil {
+			if !kmStatuses[rt.KeyManager.ToMapKey()] {
+				errs = append(errs, fmt.Errorf("runtime %s references unknown key manager %s", rt.ID, rt.KeyManager))
+			}
+		}
+	}
+	return errs
+}
+
+func sanityCheckScheduler(doc *Document, entities map[signature.MapKey]*entity.Entity) []error {
+	var errs []error
+
+	params := doc.Scheduler.Parameters
+	if params.MinValidators > params.MaxValidators {
+		errs = append(errs, fmt.Errorf("scheduler: min_validators (%d) > max_validators (%d)", params.MinValidators, params.MaxValidators))
+	}
+
+	if !params.DebugStaticValidators && len(entities) < params.ValidatorEntityThreshold {
+		errs = append(errs, fmt.Errorf("scheduler: only %d entities registered, need at least validator_entity_threshold (%d)", len(entities), params.ValidatorEntityThreshold))
+	}
+
+	return errs
+}
+
+func sanityCheckStaking(doc *Document) []error {
+	var computed quantity.Quantity
+	for _, acc := range doc.Staking.Ledger {
+		if err := computed.Add(&acc.General.Balance); err != nil {
+			return []error{fmt.Errorf("staking: failed to accumulate general balance: %w", err)}
+		}
+		if err := computed.Add(&acc.Escrow.Active.Balance); err != nil {
+			return []error{fmt.Errorf("staking: failed to accumulate active escrow balance: %w", err)}
+		}
+		if err := computed.Add(&acc.Escrow.Debonding.Balance); err != nil {
+			return []error{fmt.Errorf("staking: failed to accumulate debonding escrow balance: %w", err)}
+		}
+	}
+
+	if computed.Cmp(&doc.Staking.TotalSupply) != 0 {
+		return []error{fmt.Errorf("staking: total_supply (%s) does not match sum of ledger balances (%s)", doc.Staking.TotalSupply.String(), computed.String())}
+	}
+	return nil
+}
+
+func sanityCheckKeyManagerOperator(doc *Document, entities map[signature.MapKey]*entity.Entity) []error {
+	operator := doc.Registry.Parameters.KeyManagerOperator
+	if !operator.IsValid() {
+		return nil
+	}
+	if _, ok := entities[operator.ToMapKey()]; !ok {
+		return []error{fmt.Errorf("keymanager_operator %s is not a genesis entity", operator)}
+	}
+	return nil
+}
+
+func sanityCheckRootHash(doc *Document) []error {
+	runtimes := make(map[signature.MapKey]bool)
+	for _, sr := range doc.Registry.Runtimes {
+		var rt registry.Runtime
+		if err := sr.Open(registry.RegisterGenesisRuntimeSignatureContext, &rt); err != nil {
+			continue
+		}
+		var key signature.MapKey
+		copy(key[:], rt.ID[:])
+		runtimes[key] = true
+	}
+
+	var errs []error
+	for key, blk := range doc.RootHash.Blocks {
+		var nsKey signature.MapKey
+		copy(nsKey[:], blk.Header.Namespace[:])
+		if !runtimes[nsKey] {
+			errs = append(errs, fmt.Errorf("roothash block for namespace %s (block key %x) does not match any registered runtime", blk.Header.Namespace, key))
+		}
+	}
+	return errs
+}
+
+func sanityCheckHaltEpoch(doc *Document) []error {
+	if doc.HaltEpoch == 0 {
+		return []error{fmt.Errorf("halt_epoch is unset (must be > 0)")}
+	}
+	return nil
+}
@@ -45,12 +45,19 @@ var (
 	// for the given epoch does not exist.
 	ErrNoSuchEphemeralSecret = errors.New(ModuleName, 3, "keymanager: no such ephemeral secret")
 
+	// ErrNoSuchMasterSecret is the error returned when a key manager master secret
+	// for the given generation does not exist.
+	ErrNoSuchMasterSecret = errors.New(ModuleName, 4, "keymanager: no such master secret")
+
 	// MethodUpdatePolicy is the method name for policy updates.
 	MethodUpdatePolicy = transaction.NewMethodName(ModuleName, "UpdatePolicy", SignedPolicySGX{})
 
 	// MethodPublishEphemeralSecret is the method name for publishing ephemeral secret.
 	MethodPublishEphemeralSecret = transaction.NewMethodName(ModuleName, "PublishEphemeralSecret", EncryptedEphemeralSecret{})
 
+	// MethodPublishMasterSecret is the method name for publishing a master secret rotation proposal.
+	MethodPublishMasterSecret = transaction.NewMethodName(ModuleName, "PublishMasterSecret", EncryptedMasterSecret{})
+
 	// InsecureRAK is the insecure hardcoded key manager public key, used
 	// in insecure builds when a RAK is unavailable.
 	InsecureRAK signature.PublicKey
@@ -67,6 +74,7 @@ var (
 	Methods = []transaction.MethodName{
 		MethodUpdatePolicy,
 		MethodPublishEphemeralSecret,
+		MethodPublishMasterSecret,
 	}
 
 	// RPCMethodInit is the name of the `init` method.
@@ -84,6 +92,24 @@ var (
 	// RPCMethodLoadEphemeralSecret is the name of the `load_ephemeral_secret` RPC method.
 	RPCMethodLoadEphemeralSecret = "load_ephemeral_secret"
 
+	// RPCMethodGenerateMasterSecret is the name of the `generate_master_secret` RPC method.
+	RPCMethodGenerateMasterSecret = "generate_master_secret"
+
+	// RPCMethodLoadMasterSecret is the name of the `load_master_secret` RPC method.
+	RPCMethodLoadMasterSecret = "load_master_secret"
+
+	// RPCMethodSealState is the name of the `seal_state` RPC method.
+	RPCMethodSealState = "seal_state"
+
+	// RPCMethodRestoreState is the name of the `restore_state` RPC method.
+	RPCMethodRestoreState = "restore_state"
+
+	// RPCMethodEncrypt is the name of the `encrypt` RPC method.
+	RPCMethodEncrypt = "encrypt"
+
+	// RPCMethodDecrypt is the name of the `decrypt` RPC method.
+	RPCMethodDecrypt = "decrypt"
+
 	// initResponseSignatureContext is the context used to sign key manager init responses.
 	initResponseSignatureContext = signature.NewContext("oasis-core/keymanager: init response")
 )
@@ -95,6 +121,9 @@ const (
 	// GasOpPublishEphemeralSecret is the gas operation identifier for publishing
 	// key manager ephemeral secret.
 	GasOpPublishEphemeralSecret transaction.Op = "publish_ephemeral_secret"
+	// GasOpPublishMasterSecret is the gas operation identifier for publishing
+	// a key manager master secret rotation proposal.
+	GasOpPublishMasterSecret transaction.Op = "publish_master_secret"
 )
 
 // XXX: Define reasonable default gas costs.
@@ -103,8 +132,14 @@ const (
 var DefaultGasCosts = transaction.Costs{
 	GasOpUpdatePolicy:           1000,
 	GasOpPublishEphemeralSecret: 1000,
+	GasOpPublishMasterSecret:    1000,
 }
 
+// MinMasterSecretReplicationPercent is the minimum percentage of committee
+// enclaves that must have replicated a proposed master secret rotation
+// before it is accepted.
+const MinMasterSecretReplicationPercent = 66
+
 // KeyPairID is a 256-bit key pair identifier.
 type KeyPairID [KeyPairIDSize]byte
 
@@ -130,6 +165,17 @@ type Status struct {
 
 	// RSK is the runtime signing key of the key manager.
 	RSK *signature.PublicKey `json:"rsk,omitempty"`
+
+	// Generation is the current generation of the master secret.
+	Generation uint64 `json:"generation"`
+
+	// RotationEpoch is the epoch of the last master secret rotation.
+	RotationEpoch beacon.EpochTime `json:"rotation_epoch"`
+
+	// NextChecksum is the verification checksum of the master secret proposed
+	// for the next generation, while it is still being replicated by the
+	// committee. It is empty when there is no rotation in progress.
+	NextChecksum []byte `json:"next_checksum,omitempty"`
 }
 
 // Backend is a key manager management implementation.
@@ -154,6 +200,12 @@ type Backend interface {
 
 	// WatchEphemeralSecrets returns a channel that produces a stream of ephemeral secrets.
 	WatchEphemeralSecrets() (<-chan *SignedEncryptedEphemeralSecret, *pubsub.Subscription)
+
+	// GetMasterSecret returns the key manager master secret proposal for the given generation.
+	GetMasterSecret(context.Context, *NamespaceGenerationQuery) (*SignedEncryptedMasterSecret, error)
+
+	// WatchMasterSecrets returns a channel that produces a stream of accepted master secrets.
+	WatchMasterSecrets() (<-chan *SignedEncryptedMasterSecret, *pubsub.Subscription)
 }
 
 // NewUpdatePolicyTx creates a new policy update transaction.
@@ -166,6 +218,11 @@ func NewPublishEphemeralSecretTx(nonce uint64, fee *transaction.Fee, sigEnt *Sig
 	return transaction.NewTransaction(nonce, fee, MethodPublishEphemeralSecret, sigEnt)
 }
 
+// NewPublishMasterSecretTx creates a new publish master secret transaction.
+func NewPublishMasterSecretTx(nonce uint64, fee *transaction.Fee, sigSecret *SignedEncryptedMasterSecret) *transaction.Transaction {
+	return transaction.NewTransaction(nonce, fee, MethodPublishMasterSecret, sigSecret)
+}
+
 // InitRequest is the initialization RPC request, sent to the key manager
 // enclave.
 type InitRequest struct {
@@ -229,10 +286,47 @@ type SignedPublicKey struct {
 	Expiration *beacon.EpochTime      `json:"expiration,omitempty"`
 }
 
+// EncryptRequest is the encrypt RPC request, sent by a client runtime to have the key manager
+// enclave encrypt a plaintext under the key derived for the given (runtime, key-pair-id, epoch)
+// tuple, without ever exposing the key itself to the caller.
+type EncryptRequest struct {
+	ID        common.Namespace `json:"runtime_id"`
+	KeyPairID KeyPairID        `json:"key_pair_id"`
+	Epoch     beacon.EpochTime `json:"epoch"`
+	Plaintext []byte           `json:"plaintext"`
+}
+
+// EncryptResponse is the RPC response, returned as part of an EncryptRequest from the key
+// manager enclave.
+type EncryptResponse struct {
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// DecryptRequest is the decrypt RPC request, sent by a client runtime to have the key manager
+// enclave decrypt a ciphertext previously produced by an EncryptRequest for the same
+// (runtime, key-pair-id, epoch) tuple.
+type DecryptRequest struct {
+	ID         common.Namespace `json:"runtime_id"`
+	KeyPairID  KeyPairID        `json:"key_pair_id"`
+	Epoch      beacon.EpochTime `json:"epoch"`
+	Ciphertext []byte           `json:"ciphertext"`
+}
+
+// DecryptResponse is the RPC response, returned as part of a DecryptRequest from the key
+// manager enclave.
+type DecryptResponse struct {
+	Plaintext []byte `json:"plaintext"`
+}
+
 // GenerateEphemeralSecretRequest is the generate ephemeral secret RPC request,
 // sent to the key manager enclave.
 type GenerateEphemeralSecretRequest struct {
 	Epoch beacon.EpochTime `json:"epoch"`
+
+	// HeightProof is the VRF proof for the block height at which secret generation was
+	// attempted, verified by the enclave before it signs the generated secret so that a node
+	// cannot grind its way into generating out of turn.
+	HeightProof []byte `json:"height_proof,omitempty"`
 }
 
 // GenerateEphemeralSecretResponse is the RPC response, returned as part of
@@ -247,6 +341,28 @@ type LoadEphemeralSecretRequest struct {
 	SignedSecret SignedEncryptedEphemeralSecret `json:"signed_secret"`
 }
 
+// SealStateRequest is the seal state RPC request, sent to the key manager enclave to seal its
+// currently active policy and loaded ephemeral secrets for persistence outside the enclave.
+type SealStateRequest struct{}
+
+// SealStateResponse is the RPC response, returned as part of a SealStateRequest from the key
+// manager enclave. Sealed is opaque to the worker; it can only be decrypted by an enclave running
+// on the same platform with access to the same sealing key (e.g. the same MRENCLAVE).
+type SealStateResponse struct {
+	Sealed []byte `json:"sealed"`
+}
+
+// RestoreStateRequest is the restore state RPC request, sent to the key manager enclave on
+// startup to unseal and pre-load a previously sealed policy and set of ephemeral secrets before
+// the worker has finished consensus sync.
+type RestoreStateRequest struct {
+	Sealed []byte `json:"sealed"`
+}
+
+// RestoreStateResponse is the RPC response, returned as part of a RestoreStateRequest from the
+// key manager enclave.
+type RestoreStateResponse struct{}
+
 // VerifyExtraInfo verifies and parses the per-node + per-runtime ExtraInfo
 // blob for a key manager.
 func VerifyExtraInfo(
@@ -335,6 +451,19 @@ func (ev *EphemeralSecretPublishedEvent) EventKind() string {
 	return "ephemeral_secret"
 }
 
+// MasterSecretPublishedEvent is the key manager master secret published event.
+//
+// It is only emitted once a proposal has been replicated by at least
+// MinMasterSecretReplicationPercent of the committee's enclaves.
+type MasterSecretPublishedEvent struct {
+	Secret *SignedEncryptedMasterSecret
+}
+
+// EventKind returns a string representation of this event's kind.
+func (ev *MasterSecretPublishedEvent) EventKind() string {
+	return "master_secret"
+}
+
 func init() {
 	// Old `INSECURE_SIGNING_KEY_PKCS8`.
 	var oldTestKey signature.PublicKey
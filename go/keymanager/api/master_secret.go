@@ -0,0 +1,105 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/x25519"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+)
+
+// masterSecretSignatureContext is the context used to sign encrypted master secrets.
+var masterSecretSignatureContext = signature.NewContext("oasis-core/keymanager: master secret")
+
+// NamespaceGenerationQuery is a key manager master secret query by key
+// manager runtime ID and generation.
+type NamespaceGenerationQuery struct {
+	Height     int64            `json:"height"`
+	ID         common.Namespace `json:"id"`
+	Generation uint64           `json:"generation"`
+}
+
+// EncryptedMasterSecret is a proposed master secret rotation, encrypted to
+// the REKs of the key manager committee, analogous to EncryptedEphemeralSecret.
+type EncryptedMasterSecret struct {
+	ID         common.Namespace            `json:"runtime_id"`
+	Generation uint64                      `json:"generation"`
+	Epoch      beacon.EpochTime            `json:"epoch"`
+	Secret     map[x25519.PublicKey][]byte `json:"secret"`
+	Checksum   []byte                      `json:"checksum"`
+
+	// HeightProof is the VRF proof for the block height at which this generation was attempted,
+	// carried through from GenerateMasterSecretRequest.HeightProof so that peers receiving the
+	// published secret can verify the height was not chosen by grinding. Empty when the generating
+	// node had no proof to pass on; a peer must reject a secret that omits it.
+	HeightProof []byte `json:"height_proof"`
+}
+
+// SignedEncryptedMasterSecret is an encrypted master secret, signed by the
+// RAK of the proposing key manager enclave.
+type SignedEncryptedMasterSecret struct {
+	Secret    EncryptedMasterSecret  `json:"secret"`
+	Signature signature.RawSignature `json:"signature"`
+}
+
+// Verify verifies the signature of the encrypted secret and that it was
+// encrypted to exactly the given set of REKs, matching the validation
+// SignedEncryptedEphemeralSecret.Verify performs for the ephemeral flow.
+func (s *SignedEncryptedMasterSecret) Verify(epoch beacon.EpochTime, reks map[x25519.PublicKey]struct{}, rak signature.PublicKey) error {
+	if s.Secret.Epoch != epoch {
+		return fmt.Errorf("keymanager: master secret epoch mismatch (expected: %d got: %d)", epoch, s.Secret.Epoch)
+	}
+	if len(s.Secret.Secret) != len(reks) {
+		return fmt.Errorf("keymanager: master secret REK set mismatch (expected: %d got: %d)", len(reks), len(s.Secret.Secret))
+	}
+	for rek := range s.Secret.Secret {
+		if _, ok := reks[rek]; !ok {
+			return fmt.Errorf("keymanager: master secret encrypted to unexpected REK")
+		}
+	}
+	if !rak.Verify(masterSecretSignatureContext, cbor.Marshal(s.Secret), s.Signature[:]) {
+		return fmt.Errorf("keymanager: invalid master secret signature")
+	}
+	return nil
+}
+
+// SignEncryptedMasterSecret signs the given encrypted master secret.
+func SignEncryptedMasterSecret(signer signature.Signer, secret *EncryptedMasterSecret) (*SignedEncryptedMasterSecret, error) {
+	sig, err := signer.ContextSign(masterSecretSignatureContext, cbor.Marshal(secret))
+	if err != nil {
+		return nil, err
+	}
+	var rawSig signature.RawSignature
+	copy(rawSig[:], sig)
+	return &SignedEncryptedMasterSecret{
+		Secret:    *secret,
+		Signature: rawSig,
+	}, nil
+}
+
+// GenerateMasterSecretRequest is the generate master secret RPC request,
+// sent to the key manager enclave.
+type GenerateMasterSecretRequest struct {
+	Generation uint64           `json:"generation"`
+	Epoch      beacon.EpochTime `json:"epoch"`
+
+	// HeightProof is the VRF proof for the block height at which secret generation was
+	// attempted, verified by the enclave before it signs the generated secret, matching
+	// GenerateEphemeralSecretRequest.HeightProof.
+	HeightProof []byte `json:"height_proof,omitempty"`
+}
+
+// GenerateMasterSecretResponse is the RPC response, returned as part of a
+// GenerateMasterSecretRequest from the key manager enclave.
+type GenerateMasterSecretResponse struct {
+	SignedSecret SignedEncryptedMasterSecret `json:"signed_secret"`
+}
+
+// LoadMasterSecretRequest is the load master secret RPC request, sent to
+// the key manager enclave so it can replicate a proposed rotation.
+type LoadMasterSecretRequest struct {
+	SignedSecret SignedEncryptedMasterSecret `json:"signed_secret"`
+}
@@ -0,0 +1,337 @@
+package genesis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/oasislabs/oasis-core/go/common/crypto/signature"
+	"github.com/oasislabs/oasis-core/go/common/entity"
+	"github.com/oasislabs/oasis-core/go/common/node"
+	genesis "github.com/oasislabs/oasis-core/go/genesis/api"
+	cmdCommon "github.com/oasislabs/oasis-core/go/oasis-node/cmd/common"
+	"github.com/oasislabs/oasis-core/go/oasis-node/cmd/common/flags"
+	registry "github.com/oasislabs/oasis-core/go/registry/api"
+	staking "github.com/oasislabs/oasis-core/go/staking/api"
+)
+
+const cfgGentxDir = "gentx-dir"
+
+var collectGentxsFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
+var collectGentxsCmd = &cobra.Command{
+	Use:   "collect-gentxs",
+	Short: "collect contributed validator registrations and merge them into a skeleton genesis file",
+	Run:   doCollectGentxs,
+}
+
+// stakingAllocationSignatureContext is the domain separation context used to sign a gentx staking
+// allocation, distinct from the contexts used for entity/runtime/node gentxs.
+var stakingAllocationSignatureContext = []byte("oasis-core/genesis: gentx staking allocation")
+
+// stakingAllocation is a contributed staking allocation gentx, granting an
+// entity's general account an initial balance.
+type stakingAllocation struct {
+	ID      signature.PublicKey    `json:"id"`
+	General staking.GeneralAccount `json:"general"`
+}
+
+// SignedStakingAllocation is a signed stakingAllocation gentx, signed by the entity it allocates
+// to, the same way a node or runtime registration is signed by the entity that owns it.
+type SignedStakingAllocation struct {
+	signature.Signed
+}
+
+// Open first verifies the blob signature, and then unmarshals the blob.
+func (s *SignedStakingAllocation) Open(alloc *stakingAllocation) error {
+	return s.Signed.Open(stakingAllocationSignatureContext, alloc)
+}
+
+func doCollectGentxs(cmd *cobra.Command, args []string) {
+	var ok bool
+	defer func() {
+		if !ok {
+			os.Exit(1)
+		}
+	}()
+
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	skeletonFn := flags.GenesisFile()
+	if len(skeletonFn) == 0 {
+		logger.Error("failed to determine skeleton genesis file location")
+		return
+	}
+
+	b, err := ioutil.ReadFile(skeletonFn)
+	if err != nil {
+		logger.Error("failed to read skeleton genesis file",
+			"err", err,
+		)
+		return
+	}
+
+	var doc genesis.Document
+	if err = json.Unmarshal(b, &doc); err != nil {
+		logger.Error("failed to parse skeleton genesis file",
+			"err", err,
+		)
+		return
+	}
+
+	gentxDir := viper.GetString(cfgGentxDir)
+	if gentxDir == "" {
+		logger.Error("no gentx directory specified")
+		return
+	}
+
+	entries, err := ioutil.ReadDir(gentxDir)
+	if err != nil {
+		logger.Error("failed to list gentx directory",
+			"err", err,
+			"dir", gentxDir,
+		)
+		return
+	}
+
+	var (
+		entityFns  []string
+		runtimeFns []string
+		nodeFns    []string
+		allocFns   []string
+	)
+	for _, ent := range entries {
+		if ent.IsDir() {
+			continue
+		}
+
+		fn := filepath.Join(gentxDir, ent.Name())
+		raw, err := ioutil.ReadFile(fn)
+		if err != nil {
+			logger.Error("failed to read gentx",
+				"err", err,
+				"filename", fn,
+			)
+			return
+		}
+
+		switch classifyGentx(raw) {
+		case gentxEntity:
+			entityFns = append(entityFns, fn)
+		case gentxRuntime:
+			runtimeFns = append(runtimeFns, fn)
+		case gentxNode:
+			nodeFns = append(nodeFns, fn)
+		case gentxStakingAllocation:
+			allocFns = append(allocFns, fn)
+		default:
+			logger.Error("unrecognized gentx",
+				"filename", fn,
+			)
+			return
+		}
+	}
+
+	// AppendRegistryState builds its registry state from scratch, so run it against a throwaway
+	// document and merge the result into the skeleton's existing registry state instead of
+	// overwriting it, preserving any entities, runtimes, and nodes already present in the
+	// skeleton.
+	var scratch genesis.Document
+	if err = AppendRegistryState(&scratch, nil, runtimeFns, nodeFns, logger); err != nil {
+		logger.Error("failed to merge gentx registry state",
+			"err", err,
+		)
+		return
+	}
+	entSt := doc.Registry
+	entSt.Runtimes = append(entSt.Runtimes, scratch.Registry.Runtimes...)
+	entSt.Nodes = append(entSt.Nodes, scratch.Registry.Nodes...)
+
+	// The collected entity gentxs are not passed to AppendRegistryState above;
+	// open each one here with the same genesis entity signature context and
+	// append it unless the skeleton already holds an entity with that ID.
+	entMap := make(map[signature.MapKey]bool)
+	for _, e := range entSt.Entities {
+		var ent entity.Entity
+		if err = e.Open(registry.RegisterGenesisEntitySignatureContext, &ent); err != nil {
+			logger.Error("failed to open skeleton entity",
+				"err", err,
+			)
+			return
+		}
+		entMap[ent.ID.ToMapKey()] = true
+	}
+	for _, fn := range entityFns {
+		raw, err := ioutil.ReadFile(fn)
+		if err != nil {
+			logger.Error("failed to read gentx entity",
+				"err", err,
+				"filename", fn,
+			)
+			return
+		}
+
+		var signedEntity entity.SignedEntity
+		if err = json.Unmarshal(raw, &signedEntity); err != nil {
+			logger.Error("failed to parse gentx entity",
+				"err", err,
+				"filename", fn,
+			)
+			return
+		}
+
+		var ent entity.Entity
+		if err = signedEntity.Open(registry.RegisterGenesisEntitySignatureContext, &ent); err != nil {
+			logger.Error("failed to verify gentx entity signature",
+				"err", err,
+				"filename", fn,
+			)
+			return
+		}
+
+		idKey := ent.ID.ToMapKey()
+		if entMap[idKey] {
+			logger.Error("duplicate entity registration in gentx directory",
+				"id", ent.ID,
+				"filename", fn,
+			)
+			return
+		}
+		entMap[idKey] = true
+
+		entSt.Entities = append(entSt.Entities, &signedEntity)
+	}
+	doc.Registry = entSt
+
+	if err = mergeStakingAllocations(&doc, allocFns); err != nil {
+		logger.Error("failed to merge gentx staking allocations",
+			"err", err,
+		)
+		return
+	}
+
+	out, _ := json.Marshal(&doc)
+	if err = ioutil.WriteFile(skeletonFn, out, 0o600); err != nil {
+		logger.Error("failed to save finalized genesis document",
+			"err", err,
+		)
+		return
+	}
+
+	logger.Info("collected gentxs into finalized genesis document",
+		"entities", len(entityFns),
+		"runtimes", len(runtimeFns),
+		"nodes", len(nodeFns),
+		"staking_allocations", len(allocFns),
+	)
+
+	ok = true
+}
+
+type gentxClass int
+
+const (
+	gentxUnknown gentxClass = iota
+	gentxEntity
+	gentxRuntime
+	gentxNode
+	gentxStakingAllocation
+)
+
+// classifyGentx determines what kind of signed genesis contribution a
+// gentx file contains, by attempting to unmarshal it against each of the
+// expected shapes in turn.
+func classifyGentx(raw []byte) gentxClass {
+	var signedEntity entity.SignedEntity
+	if err := json.Unmarshal(raw, &signedEntity); err == nil && signedEntity.Signature.PublicKey.IsValid() {
+		var ent entity.Entity
+		if signedEntity.Open(registry.RegisterGenesisEntitySignatureContext, &ent) == nil {
+			return gentxEntity
+		}
+	}
+
+	var signedRuntime registry.SignedRuntime
+	if err := json.Unmarshal(raw, &signedRuntime); err == nil && signedRuntime.Signature.PublicKey.IsValid() {
+		var rt registry.Runtime
+		if signedRuntime.Open(registry.RegisterGenesisRuntimeSignatureContext, &rt) == nil {
+			return gentxRuntime
+		}
+	}
+
+	var signedNode node.SignedNode
+	if err := json.Unmarshal(raw, &signedNode); err == nil && signedNode.Signature.PublicKey.IsValid() {
+		var n node.Node
+		if signedNode.Open(registry.RegisterGenesisNodeSignatureContext, &n) == nil {
+			return gentxNode
+		}
+	}
+
+	var signedAlloc SignedStakingAllocation
+	if err := json.Unmarshal(raw, &signedAlloc); err == nil && signedAlloc.Signature.PublicKey.IsValid() {
+		var alloc stakingAllocation
+		if signedAlloc.Open(&alloc) == nil {
+			return gentxStakingAllocation
+		}
+	}
+
+	return gentxUnknown
+}
+
+// mergeStakingAllocations merges a vector of contributed, signed staking
+// allocations into the skeleton's staking genesis state, deduplicating by
+// entity ID the same way the registry entity merge above does.
+func mergeStakingAllocations(doc *genesis.Document, fns []string) error {
+	if doc.Staking.Ledger == nil {
+		doc.Staking.Ledger = make(map[signature.MapKey]*staking.Account)
+	}
+
+	for _, fn := range fns {
+		raw, err := ioutil.ReadFile(fn)
+		if err != nil {
+			return fmt.Errorf("failed to read gentx staking allocation %s: %w", fn, err)
+		}
+
+		var signedAlloc SignedStakingAllocation
+		if err = json.Unmarshal(raw, &signedAlloc); err != nil {
+			return fmt.Errorf("failed to parse gentx staking allocation %s: %w", fn, err)
+		}
+
+		var alloc stakingAllocation
+		if err = signedAlloc.Open(&alloc); err != nil {
+			return fmt.Errorf("failed to verify gentx staking allocation signature %s: %w", fn, err)
+		}
+		if signedAlloc.Signature.PublicKey != alloc.ID {
+			return fmt.Errorf("gentx staking allocation %s is not signed by the entity it allocates to", fn)
+		}
+
+		idKey := alloc.ID.ToMapKey()
+		if _, ok := doc.Staking.Ledger[idKey]; ok {
+			return fmt.Errorf("duplicate staking allocation for entity %s", alloc.ID)
+		}
+		doc.Staking.Ledger[idKey] = &staking.Account{
+			General: alloc.General,
+		}
+
+		if err = doc.Staking.TotalSupply.Add(&alloc.General.Balance); err != nil {
+			return fmt.Errorf("failed to update total supply for entity %s: %w", alloc.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	collectGentxsFlags.String(cfgGentxDir, "", "directory of contributed gentx files to collect")
+	_ = viper.BindPFlags(collectGentxsFlags)
+	collectGentxsFlags.AddFlagSet(flags.GenesisFileFlags)
+
+	collectGentxsCmd.Flags().AddFlagSet(collectGentxsFlags)
+}
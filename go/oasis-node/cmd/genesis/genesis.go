@@ -208,9 +208,30 @@ func doInitGenesis(cmd *cobra.Command, args []string) {
 		MaxTxSize:          viper.GetSizeInBytes(cfgConsensusMaxTxSizeBytes),
 	}
 
-	// TODO: Ensure consistency/sanity.
+	if err := genesis.SanityCheck(doc); err != nil {
+		logger.Error("generated genesis document failed sanity check",
+			"err", err,
+		)
+		return
+	}
+
+	appState, err := genesis.CanonicalMarshal(doc)
+	if err != nil {
+		logger.Error("failed to canonicalize genesis document",
+			"err", err,
+		)
+		return
+	}
 
-	b, _ := json.Marshal(doc)
+	tmDoc, err := layeredFromAppState(doc, appState)
+	if err != nil {
+		logger.Error("failed to build tendermint genesis envelope",
+			"err", err,
+		)
+		return
+	}
+
+	b, _ := json.Marshal(tmDoc)
 	if err := ioutil.WriteFile(f, b, 0600); err != nil {
 		logger.Error("failed to save generated genesis document",
 			"err", err,
@@ -564,6 +585,38 @@ func doDumpGenesis(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	var doc genesis.Document
+	if err = json.Unmarshal(result.Json, &doc); err != nil {
+		logger.Error("failed to parse dumped genesis document",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
+	appState, err := genesis.CanonicalMarshal(&doc)
+	if err != nil {
+		logger.Error("failed to canonicalize dumped genesis document",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
+	tmDoc, err := layeredFromAppState(&doc, appState)
+	if err != nil {
+		logger.Error("failed to build tendermint genesis envelope",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
+	out, err := json.Marshal(tmDoc)
+	if err != nil {
+		logger.Error("failed to marshal tendermint genesis envelope",
+			"err", err,
+		)
+		os.Exit(1)
+	}
+
 	w, shouldClose, err := cmdCommon.GetOutputWriter(cmd, flags.CfgGenesisFile)
 	if err != nil {
 		logger.Error("failed to get writer for genesis file",
@@ -575,7 +628,7 @@ func doDumpGenesis(cmd *cobra.Command, args []string) {
 		defer w.Close()
 	}
 
-	if _, err = w.Write(result.Json); err != nil {
+	if _, err = w.Write(out); err != nil {
 		logger.Error("failed to write genesis file",
 			"err", err,
 		)
@@ -592,6 +645,7 @@ func Register(parentCmd *cobra.Command) {
 	for _, v := range []*cobra.Command{
 		initGenesisCmd,
 		dumpGenesisCmd,
+		collectGentxsCmd,
 	} {
 		genesisCmd.AddCommand(v)
 	}
@@ -0,0 +1,88 @@
+package genesis
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	genesis "github.com/oasislabs/oasis-core/go/genesis/api"
+	cmdCommon "github.com/oasislabs/oasis-core/go/oasis-node/cmd/common"
+	"github.com/oasislabs/oasis-core/go/oasis-node/cmd/common/flags"
+)
+
+var checkGenesisCmd = &cobra.Command{
+	Use:   "check",
+	Short: "sanity check a genesis file",
+	Run:   doCheckGenesis,
+}
+
+func doCheckGenesis(cmd *cobra.Command, args []string) {
+	var ok bool
+	defer func() {
+		if !ok {
+			os.Exit(1)
+		}
+	}()
+
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	f := flags.GenesisFile()
+	if len(f) == 0 {
+		logger.Error("failed to determine genesis file location")
+		return
+	}
+
+	doc, err := loadGenesisDocument(f)
+	if err != nil {
+		logger.Error("failed to load genesis document",
+			"err", err,
+			"filename", f,
+		)
+		return
+	}
+
+	if err = genesis.SanityCheck(doc); err != nil {
+		logger.Error("genesis document failed sanity check",
+			"err", err,
+			"filename", f,
+		)
+		return
+	}
+
+	logger.Info("genesis document passed sanity check",
+		"filename", f,
+	)
+
+	ok = true
+}
+
+// loadGenesisDocument loads a genesis.Document from either the flat or the
+// layered CometBFT-style representation.
+func loadGenesisDocument(fn string) (*genesis.Document, error) {
+	b, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	if isLayeredGenesis(b) {
+		var tmDoc tendermintGenesisDoc
+		if err = json.Unmarshal(b, &tmDoc); err != nil {
+			return nil, err
+		}
+		return documentFromLayered(&tmDoc)
+	}
+
+	var doc genesis.Document
+	if err = json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func init() {
+	genesisCmd.AddCommand(checkGenesisCmd)
+}
@@ -0,0 +1,177 @@
+package genesis
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	genesis "github.com/oasislabs/oasis-core/go/genesis/api"
+	cmdCommon "github.com/oasislabs/oasis-core/go/oasis-node/cmd/common"
+)
+
+var convertGenesisCmd = &cobra.Command{
+	Use:   "convert <in.json> <out.json>",
+	Short: "convert between the flat and CometBFT-style layered genesis formats",
+	Args:  cobra.ExactArgs(2),
+	Run:   doConvertGenesis,
+}
+
+// tendermintGenesisDoc is the top-level CometBFT/Tendermint genesis envelope,
+// carrying the consensus-layer parameters alongside the Oasis-specific
+// `app_state` produced by doInitGenesis/doDumpGenesis.
+type tendermintGenesisDoc struct {
+	ChainID         string          `json:"chain_id"`
+	GenesisTime     time.Time       `json:"genesis_time"`
+	InitialHeight   int64           `json:"initial_height"`
+	ConsensusParams tmConsensus     `json:"consensus_params"`
+	Validators      []tmValidator   `json:"validators,omitempty"`
+	AppState        json.RawMessage `json:"app_state"`
+}
+
+// tmConsensus mirrors the subset of Tendermint consensus parameters that are
+// derived from the Oasis consensus genesis state.
+type tmConsensus struct {
+	Block struct {
+		MaxBytes int64 `json:"max_bytes"`
+	} `json:"block"`
+}
+
+// tmValidator is a Tendermint validator entry. These are populated by the
+// scheduler once the full registry state (and hence the elected validator
+// set) is available; a freshly assembled genesis document carries none.
+type tmValidator struct {
+	Address string `json:"address"`
+	PubKey  struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"pub_key"`
+	Power int64 `json:"power"`
+}
+
+// layeredFromDocument wraps a flat genesis.Document into the layered
+// CometBFT-style envelope, with `app_state` encoded via the regular (non-
+// canonical) JSON marshaler.
+func layeredFromDocument(doc *genesis.Document) (*tendermintGenesisDoc, error) {
+	appState, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return layeredFromAppState(doc, appState)
+}
+
+// layeredFromAppState wraps a flat genesis.Document into the layered
+// CometBFT-style envelope given an already-encoded `app_state` blob, so
+// callers can pipe in the canonically-encoded form.
+func layeredFromAppState(doc *genesis.Document, appState []byte) (*tendermintGenesisDoc, error) {
+	tmDoc := &tendermintGenesisDoc{
+		ChainID:     doc.ChainID,
+		GenesisTime: doc.Time,
+		AppState:    appState,
+	}
+	tmDoc.ConsensusParams.Block.MaxBytes = doc.Consensus.MaxTxSize
+
+	return tmDoc, nil
+}
+
+// documentFromLayered unwraps the `app_state` of a layered CometBFT-style
+// genesis document back into a flat genesis.Document.
+func documentFromLayered(tmDoc *tendermintGenesisDoc) (*genesis.Document, error) {
+	var doc genesis.Document
+	if err := json.Unmarshal(tmDoc.AppState, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func doConvertGenesis(cmd *cobra.Command, args []string) {
+	var ok bool
+	defer func() {
+		if !ok {
+			os.Exit(1)
+		}
+	}()
+
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	inFn, outFn := args[0], args[1]
+
+	b, err := ioutil.ReadFile(inFn)
+	if err != nil {
+		logger.Error("failed to read input genesis document",
+			"err", err,
+			"filename", inFn,
+		)
+		return
+	}
+
+	var out []byte
+	switch isLayeredGenesis(b) {
+	case true:
+		var tmDoc tendermintGenesisDoc
+		if err = json.Unmarshal(b, &tmDoc); err != nil {
+			logger.Error("failed to parse layered genesis document",
+				"err", err,
+			)
+			return
+		}
+		doc, derr := documentFromLayered(&tmDoc)
+		if derr != nil {
+			logger.Error("failed to unwrap app_state",
+				"err", derr,
+			)
+			return
+		}
+		out, err = json.Marshal(doc)
+	case false:
+		var doc genesis.Document
+		if err = json.Unmarshal(b, &doc); err != nil {
+			logger.Error("failed to parse flat genesis document",
+				"err", err,
+			)
+			return
+		}
+		var tmDoc *tendermintGenesisDoc
+		tmDoc, err = layeredFromDocument(&doc)
+		if err == nil {
+			out, err = json.Marshal(tmDoc)
+		}
+	}
+	if err != nil {
+		logger.Error("failed to convert genesis document",
+			"err", err,
+		)
+		return
+	}
+
+	if err = ioutil.WriteFile(outFn, out, 0o600); err != nil {
+		logger.Error("failed to write converted genesis document",
+			"err", err,
+			"filename", outFn,
+		)
+		return
+	}
+
+	ok = true
+}
+
+// isLayeredGenesis reports whether the given genesis document bytes are in
+// the layered CometBFT-style format, identified by the presence of the
+// top-level `app_state` field.
+func isLayeredGenesis(b []byte) bool {
+	var probe struct {
+		AppState json.RawMessage `json:"app_state"`
+	}
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return false
+	}
+	return len(probe.AppState) > 0
+}
+
+func init() {
+	genesisCmd.AddCommand(convertGenesisCmd)
+}
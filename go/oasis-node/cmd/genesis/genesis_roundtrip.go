@@ -0,0 +1,209 @@
+package genesis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/oasislabs/oasis-core/go/common/entity"
+	"github.com/oasislabs/oasis-core/go/common/node"
+	genesis "github.com/oasislabs/oasis-core/go/genesis/api"
+	cmdCommon "github.com/oasislabs/oasis-core/go/oasis-node/cmd/common"
+	registry "github.com/oasislabs/oasis-core/go/registry/api"
+	"github.com/oasislabs/oasis-core/go/roothash/api/block"
+)
+
+var roundtripGenesisCmd = &cobra.Command{
+	Use:   "roundtrip <in.json>",
+	Short: "re-derive a genesis document from its own exported state and verify it is unchanged",
+	Args:  cobra.ExactArgs(1),
+	Run:   doRoundtripGenesis,
+}
+
+func doRoundtripGenesis(cmd *cobra.Command, args []string) {
+	var ok bool
+	defer func() {
+		if !ok {
+			os.Exit(1)
+		}
+	}()
+
+	if err := cmdCommon.Init(); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	doc, err := loadGenesisDocument(args[0])
+	if err != nil {
+		logger.Error("failed to load genesis document",
+			"err", err,
+			"filename", args[0],
+		)
+		return
+	}
+
+	if err = GenesisRoundTrip(doc); err != nil {
+		logger.Error("genesis document did not round-trip cleanly",
+			"err", err,
+		)
+		return
+	}
+
+	logger.Info("genesis document round-tripped cleanly")
+
+	ok = true
+}
+
+// GenesisRoundTrip takes an input genesis document, runs it back through
+// AppendRegistryState / AppendRootHashState / AppendKeyManagerState /
+// AppendStakingState as if freshly initializing, and asserts the result is
+// identical to the input under the canonical encoder. It also re-verifies
+// every SignedEntity / SignedRuntime / SignedNode still opens against its
+// original signature context, and that staking balances and TotalSupply are
+// preserved exactly.
+func GenesisRoundTrip(doc *genesis.Document) error {
+	dir, err := ioutil.TempDir("", "genesis-roundtrip")
+	if err != nil {
+		return fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	entityFns, err := dumpJSONFiles(dir, "entity", doc.Registry.Entities)
+	if err != nil {
+		return err
+	}
+	runtimeFns, err := dumpJSONFiles(dir, "runtime", doc.Registry.Runtimes)
+	if err != nil {
+		return err
+	}
+	nodeFns, err := dumpJSONFiles(dir, "node", doc.Registry.Nodes)
+	if err != nil {
+		return err
+	}
+
+	blocks := make([]*block.Block, 0, len(doc.RootHash.Blocks))
+	for _, blk := range doc.RootHash.Blocks {
+		blocks = append(blocks, blk)
+	}
+	rootHashFn, err := dumpJSONFile(dir, "roothash-blocks", blocks)
+	if err != nil {
+		return err
+	}
+
+	kmFns, err := dumpJSONFiles(dir, "km-status", doc.KeyManager.Statuses)
+	if err != nil {
+		return err
+	}
+
+	stakingFn, err := dumpJSONFile(dir, "staking", doc.Staking)
+	if err != nil {
+		return err
+	}
+
+	rebuilt := &genesis.Document{
+		ChainID:   doc.ChainID,
+		Time:      doc.Time,
+		HaltEpoch: doc.HaltEpoch,
+	}
+	if err = AppendRegistryState(rebuilt, entityFns, runtimeFns, nodeFns, logger); err != nil {
+		return fmt.Errorf("failed to re-derive registry state: %w", err)
+	}
+	if err = AppendRootHashState(rebuilt, []string{rootHashFn}, logger); err != nil {
+		return fmt.Errorf("failed to re-derive roothash state: %w", err)
+	}
+	if err = AppendKeyManagerState(rebuilt, kmFns, logger); err != nil {
+		return fmt.Errorf("failed to re-derive key manager state: %w", err)
+	}
+	if err = AppendStakingState(rebuilt, stakingFn, logger); err != nil {
+		return fmt.Errorf("failed to re-derive staking state: %w", err)
+	}
+	// The Append* helpers take each section's parameters from command-line flags rather than
+	// from the exported state, so carry them over from the original document.
+	rebuilt.Registry.Parameters = doc.Registry.Parameters
+	rebuilt.RootHash.Parameters = doc.RootHash.Parameters
+	rebuilt.KeyManager.Parameters = doc.KeyManager.Parameters
+	rebuilt.Scheduler = doc.Scheduler
+	rebuilt.Beacon = doc.Beacon
+	rebuilt.EpochTime = doc.EpochTime
+	rebuilt.Consensus = doc.Consensus
+
+	for _, se := range rebuilt.Registry.Entities {
+		var ent entity.Entity
+		if err = se.Open(registry.RegisterGenesisEntitySignatureContext, &ent); err != nil {
+			return fmt.Errorf("entity signature failed to re-open after round-trip: %w", err)
+		}
+	}
+	for _, sr := range rebuilt.Registry.Runtimes {
+		var rt registry.Runtime
+		if err = sr.Open(registry.RegisterGenesisRuntimeSignatureContext, &rt); err != nil {
+			return fmt.Errorf("runtime signature failed to re-open after round-trip: %w", err)
+		}
+	}
+	for _, sn := range rebuilt.Registry.Nodes {
+		var n node.Node
+		if err = sn.Open(registry.RegisterGenesisNodeSignatureContext, &n); err != nil {
+			return fmt.Errorf("node signature failed to re-open after round-trip: %w", err)
+		}
+	}
+
+	if rebuilt.Staking.TotalSupply.Cmp(&doc.Staking.TotalSupply) != 0 {
+		return fmt.Errorf("total_supply changed across round-trip: %s != %s", rebuilt.Staking.TotalSupply.String(), doc.Staking.TotalSupply.String())
+	}
+	for key, acc := range doc.Staking.Ledger {
+		racc, ok := rebuilt.Staking.Ledger[key]
+		if !ok {
+			return fmt.Errorf("ledger entry %x missing after round-trip", key)
+		}
+		if acc.General.Balance.Cmp(&racc.General.Balance) != 0 {
+			return fmt.Errorf("ledger entry %x general balance changed across round-trip", key)
+		}
+	}
+
+	origCanon, err := genesis.CanonicalMarshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to canonicalize original document: %w", err)
+	}
+	rebuiltCanon, err := genesis.CanonicalMarshal(rebuilt)
+	if err != nil {
+		return fmt.Errorf("failed to canonicalize rebuilt document: %w", err)
+	}
+	if string(origCanon) != string(rebuiltCanon) {
+		return fmt.Errorf("canonical encoding changed across round-trip")
+	}
+
+	return nil
+}
+
+// dumpJSONFiles writes each element of a slice to its own scratch file and
+// returns the resulting filenames, mirroring the per-file inputs that
+// AppendRegistryState/AppendKeyManagerState expect.
+func dumpJSONFiles[T any](dir, prefix string, items []T) ([]string, error) {
+	fns := make([]string, 0, len(items))
+	for i, item := range items {
+		fn, err := dumpJSONFile(dir, fmt.Sprintf("%s-%d", prefix, i), item)
+		if err != nil {
+			return nil, err
+		}
+		fns = append(fns, fn)
+	}
+	return fns, nil
+}
+
+func dumpJSONFile(dir, name string, v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	fn := filepath.Join(dir, name+".json")
+	if err = ioutil.WriteFile(fn, b, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return fn, nil
+}
+
+func init() {
+	genesisCmd.AddCommand(roundtripGenesisCmd)
+}
@@ -0,0 +1,21 @@
+package genesis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	genesis "github.com/oasislabs/oasis-core/go/genesis/api"
+)
+
+func TestGenesisRoundTrip(t *testing.T) {
+	doc := &genesis.Document{
+		ChainID:   "test: genesis roundtrip",
+		Time:      time.Now().UTC(),
+		HaltEpoch: 1,
+	}
+
+	err := GenesisRoundTrip(doc)
+	require.NoError(t, err, "genesis document should round-trip without changes")
+}
@@ -0,0 +1,157 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/keymanager/api"
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/env"
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/oasis"
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/scenario"
+)
+
+// KeymanagerKeyGeneration is the key manager deterministic/ephemeral key generation scenario.
+var KeymanagerKeyGeneration scenario.Scenario = newKeymanagerKeyGenerationImpl()
+
+type keymanagerKeyGenerationImpl struct {
+	runtimeImpl
+}
+
+func newKeymanagerKeyGenerationImpl() scenario.Scenario {
+	return &keymanagerKeyGenerationImpl{
+		runtimeImpl: *newRuntimeImpl("keymanager-key-generation", "simple-keyvalue-client", nil),
+	}
+}
+
+func (sc *keymanagerKeyGenerationImpl) Clone() scenario.Scenario {
+	return &keymanagerKeyGenerationImpl{
+		runtimeImpl: *sc.runtimeImpl.Clone().(*runtimeImpl),
+	}
+}
+
+func (sc *keymanagerKeyGenerationImpl) Fixture() (*oasis.NetworkFixture, error) {
+	f, err := sc.runtimeImpl.Fixture()
+	if err != nil {
+		return nil, err
+	}
+
+	// Shorten the epoch interval so we observe several epoch transitions, and
+	// hence several ephemeral secret rotations, over the course of the test.
+	f.Network.EpochtimeTendermintInterval = 10
+	return f, nil
+}
+
+func (sc *keymanagerKeyGenerationImpl) Run(childEnv *env.Env) error {
+	ctx := context.Background()
+
+	if err := sc.Net.Start(); err != nil {
+		return err
+	}
+	if err := sc.Net.Controller().WaitNodesRegistered(ctx, len(sc.Net.Validators())); err != nil {
+		return err
+	}
+
+	keyPairIDs := []api.KeyPairID{
+		{0x01}, {0x02}, {0x03},
+	}
+
+	// Long-term keys must be stable across epochs.
+	longTerm := make(map[api.KeyPairID]api.SignedPublicKey)
+	for _, kpID := range keyPairIDs {
+		pk, err := sc.getPublicKey(ctx, kpID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch long-term public key: %w", err)
+		}
+		longTerm[kpID] = *pk
+	}
+
+	var lastEphemeral map[api.KeyPairID]api.SignedPublicKey
+	for epoch := 0; epoch < 3; epoch++ {
+		if err := sc.Net.Controller().Beacon.WaitEpoch(ctx, sc.Net.Controller().Consensus.Beacon()); err != nil {
+			return fmt.Errorf("failed to wait for epoch transition: %w", err)
+		}
+
+		ephemeral := make(map[api.KeyPairID]api.SignedPublicKey)
+		for _, kpID := range keyPairIDs {
+			pk, err := sc.getPublicKey(ctx, kpID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch long-term public key in epoch %d: %w", epoch, err)
+			}
+			if !pk.Key.Equal(longTerm[kpID].Key) {
+				return fmt.Errorf("long-term key for %x changed across epoch %d", kpID, epoch)
+			}
+
+			ephPk, err := sc.getPublicEphemeralKey(ctx, kpID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch ephemeral public key in epoch %d: %w", epoch, err)
+			}
+			ephemeral[kpID] = *ephPk
+
+			if last, ok := lastEphemeral[kpID]; ok && last.Key.Equal(ephPk.Key) {
+				return fmt.Errorf("ephemeral key for %x did not rotate across epoch %d", kpID, epoch)
+			}
+		}
+		lastEphemeral = ephemeral
+	}
+
+	// Kill and restart a key manager node, and make sure it replicates the
+	// secrets it missed and serves the same public keys as before.
+	km := sc.Net.Keymanagers()[0]
+	sc.Logger.Info("restarting key manager node")
+	if err := km.Restart(ctx); err != nil {
+		return fmt.Errorf("failed to restart key manager: %w", err)
+	}
+	if err := sc.Net.Controller().WaitNodesRegistered(ctx, len(sc.Net.Validators())); err != nil {
+		return err
+	}
+	for kpID, want := range lastEphemeral {
+		got, err := sc.getPublicEphemeralKey(ctx, kpID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch ephemeral public key after restart: %w", err)
+		}
+		if !got.Key.Equal(want.Key) {
+			return fmt.Errorf("ephemeral key for %x changed after key manager restart", kpID)
+		}
+	}
+
+	// Requests for an already-expired ephemeral epoch must fail.
+	if _, err := sc.getPublicEphemeralKeyForEpoch(ctx, keyPairIDs[0], 1); err == nil {
+		return fmt.Errorf("expected request for expired ephemeral epoch to fail")
+	}
+
+	return nil
+}
+
+// getPublicKey fetches a runtime's long-term public key for the given key pair ID.
+func (sc *keymanagerKeyGenerationImpl) getPublicKey(ctx context.Context, kpID api.KeyPairID) (*api.SignedPublicKey, error) {
+	ctrl, err := oasis.NewController(sc.Net.Clients()[0].SocketPath())
+	if err != nil {
+		return nil, err
+	}
+	return ctrl.Keymanager.GetPublicKey(ctx, &api.EphemeralKeyRequest{
+		ID:        runtimeID,
+		KeyPairID: kpID,
+	})
+}
+
+// getPublicEphemeralKey fetches a runtime's current ephemeral public key for the given key pair ID.
+func (sc *keymanagerKeyGenerationImpl) getPublicEphemeralKey(ctx context.Context, kpID api.KeyPairID) (*api.SignedPublicKey, error) {
+	epoch, err := sc.Net.Controller().Consensus.Beacon().GetEpoch(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	return sc.getPublicEphemeralKeyForEpoch(ctx, kpID, epoch)
+}
+
+func (sc *keymanagerKeyGenerationImpl) getPublicEphemeralKeyForEpoch(ctx context.Context, kpID api.KeyPairID, epoch beacon.EpochTime) (*api.SignedPublicKey, error) {
+	ctrl, err := oasis.NewController(sc.Net.Clients()[0].SocketPath())
+	if err != nil {
+		return nil, err
+	}
+	return ctrl.Keymanager.GetPublicEphemeralKey(ctx, &api.EphemeralKeyRequest{
+		ID:        runtimeID,
+		KeyPairID: kpID,
+		Epoch:     epoch,
+	})
+}
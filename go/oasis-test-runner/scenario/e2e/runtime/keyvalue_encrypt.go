@@ -0,0 +1,164 @@
+package runtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/x25519"
+	"github.com/oasisprotocol/deoxysii"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/keymanager/api"
+	"github.com/oasisprotocol/oasis-core/go/oasis-test-runner/oasis"
+)
+
+// sealedBox is a deoxys-ii box sealed to a key manager ephemeral public key,
+// keyed off (runtime_id, key_pair_id, epoch), the same derivation the key
+// manager worker uses for EphemeralKeyRequest. Epoch is carried alongside the
+// ciphertext so that a reader fetches the same ephemeral key the box was
+// sealed under, rather than whatever epoch happens to be current by the time
+// it reads the value back.
+type sealedBox struct {
+	Epoch         beacon.EpochTime         `json:"epoch"`
+	PeerPublicKey x25519.PublicKey         `json:"peer_public_key"`
+	Nonce         [deoxysii.NonceSize]byte `json:"nonce"`
+	Ciphertext    []byte                   `json:"ciphertext"`
+}
+
+// submitKeyValueRuntimeEncryptTx fetches the key manager's current ephemeral
+// public key for (id, kpID), encrypts plaintext under it, and submits the
+// sealed box as a runtime transaction under key.
+func (sc *runtimeImpl) submitKeyValueRuntimeEncryptTx(ctx context.Context, id common.Namespace, kpID api.KeyPairID, key, plaintext string) error {
+	pk, epoch, err := sc.fetchEphemeralPublicKey(ctx, id, kpID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch ephemeral public key: %w", err)
+	}
+
+	var ourPriv x25519.PrivateKey
+	if _, err = rand.Read(ourPriv[:]); err != nil {
+		return fmt.Errorf("failed to generate ephemeral keypair: %w", err)
+	}
+	ourPub := ourPriv.Public()
+
+	box := &sealedBox{
+		Epoch:         epoch,
+		PeerPublicKey: *ourPub,
+	}
+	if _, err = rand.Read(box.Nonce[:]); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	aead, err := deoxysii.New(x25519.SharedKey(&ourPriv, &pk.Key)[:])
+	if err != nil {
+		return fmt.Errorf("failed to derive AEAD: %w", err)
+	}
+	box.Ciphertext = aead.Seal(nil, box.Nonce[:], []byte(plaintext), nil)
+
+	if err = sc.submitKeyValueRuntimeInsertTx(ctx, id, key, string(marshalSealedBox(box))); err != nil {
+		return fmt.Errorf("failed to submit encrypted runtime tx: %w", err)
+	}
+
+	return nil
+}
+
+// submitKeyValueRuntimeDecryptTx queries the runtime for the sealed box it
+// stored under key, decrypts it with our own ephemeral private key
+// counterpart to the peer public key recorded in the box, and compares the
+// result against want. The ephemeral public key is fetched pinned to the
+// epoch recorded in the box, not whatever epoch is current when this runs.
+func (sc *runtimeImpl) submitKeyValueRuntimeDecryptTx(ctx context.Context, id common.Namespace, kpID api.KeyPairID, key string, ourPriv *x25519.PrivateKey, want string) error {
+	raw, err := sc.submitKeyValueRuntimeGetTx(ctx, id, key)
+	if err != nil {
+		return fmt.Errorf("failed to query encrypted runtime value: %w", err)
+	}
+
+	box, err := unmarshalSealedBox([]byte(raw))
+	if err != nil {
+		return fmt.Errorf("failed to parse sealed box: %w", err)
+	}
+
+	pk, err := sc.fetchEphemeralPublicKeyForEpoch(ctx, id, kpID, box.Epoch)
+	if err != nil {
+		return fmt.Errorf("failed to fetch ephemeral public key: %w", err)
+	}
+
+	aead, err := deoxysii.New(x25519.SharedKey(ourPriv, &pk.Key)[:])
+	if err != nil {
+		return fmt.Errorf("failed to derive AEAD: %w", err)
+	}
+
+	plaintext, err := aead.Open(nil, box.Nonce[:], box.Ciphertext, nil)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt runtime response: %w", err)
+	}
+	if string(plaintext) != want {
+		return fmt.Errorf("decrypted plaintext mismatch (got: %q want: %q)", plaintext, want)
+	}
+
+	return nil
+}
+
+// fetchEphemeralPublicKey fetches the key manager's ephemeral public key for
+// a (runtime, key pair ID), pinned to the current epoch, returning the epoch
+// it was fetched for so a caller can carry it alongside whatever it seals.
+func (sc *runtimeImpl) fetchEphemeralPublicKey(ctx context.Context, id common.Namespace, kpID api.KeyPairID) (*api.SignedPublicKey, beacon.EpochTime, error) {
+	ctrl, err := oasis.NewController(sc.Net.Clients()[0].SocketPath())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	epoch, err := ctrl.Consensus.Beacon().GetEpoch(ctx, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pk, err := sc.fetchEphemeralPublicKeyForEpoch(ctx, id, kpID, epoch)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pk, epoch, nil
+}
+
+// fetchEphemeralPublicKeyForEpoch fetches the key manager's ephemeral public
+// key for a (runtime, key pair ID) pinned to an already-known epoch, so a
+// decrypting reader fetches the same key a box was sealed under.
+func (sc *runtimeImpl) fetchEphemeralPublicKeyForEpoch(ctx context.Context, id common.Namespace, kpID api.KeyPairID, epoch beacon.EpochTime) (*api.SignedPublicKey, error) {
+	ctrl, err := oasis.NewController(sc.Net.Clients()[0].SocketPath())
+	if err != nil {
+		return nil, err
+	}
+
+	return ctrl.Keymanager.GetPublicEphemeralKey(ctx, &api.EphemeralKeyRequest{
+		ID:        id,
+		KeyPairID: kpID,
+		Epoch:     epoch,
+	})
+}
+
+func marshalSealedBox(box *sealedBox) []byte {
+	out := make([]byte, 0, 8+len(box.PeerPublicKey)+len(box.Nonce)+len(box.Ciphertext))
+	var epochBytes [8]byte
+	binary.BigEndian.PutUint64(epochBytes[:], uint64(box.Epoch))
+	out = append(out, epochBytes[:]...)
+	out = append(out, box.PeerPublicKey[:]...)
+	out = append(out, box.Nonce[:]...)
+	out = append(out, box.Ciphertext...)
+	return out
+}
+
+func unmarshalSealedBox(data []byte) (*sealedBox, error) {
+	var box sealedBox
+	headerSize := 8 + len(box.PeerPublicKey) + len(box.Nonce)
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("sealed box too short (got %d bytes, want at least %d)", len(data), headerSize)
+	}
+
+	box.Epoch = beacon.EpochTime(binary.BigEndian.Uint64(data[:8]))
+	copy(box.PeerPublicKey[:], data[8:8+len(box.PeerPublicKey)])
+	copy(box.Nonce[:], data[8+len(box.PeerPublicKey):headerSize])
+	box.Ciphertext = append([]byte{}, data[headerSize:]...)
+	return &box, nil
+}
@@ -160,5 +160,46 @@ func (sc *storageSyncImpl) Run(childEnv *env.Env) error {
 		return fmt.Errorf("incorrect number of valid checkpoints (expected: >=2 got: %d)", validCps)
 	}
 
+	// An untrusted node bootstrapping off this checkpoint set should be able to verify the
+	// resulting root against the on-chain checkpoint oracle before trusting it for reads.
+	if err = sc.verifyCheckpointAgainstOracle(ctx, ctrl, lastCheckpoint); err != nil {
+		return fmt.Errorf("failed to verify checkpoint against oracle: %w", err)
+	}
+
+	return nil
+}
+
+// verifyCheckpointAgainstOracle checks that the storage root served as of the checkpoint at
+// round matches the (runtime_id, round, storage_root_hash, io_root_hash) tuple most recently
+// accepted by the on-chain checkpoint oracle, so a light client syncing from this checkpoint set
+// need not replay from genesis to trust it.
+func (sc *storageSyncImpl) verifyCheckpointAgainstOracle(ctx context.Context, ctrl *oasis.Controller, round uint64) error {
+	cp, err := ctrl.CheckpointOracle.GetLatestCheckpoint(ctx, runtimeID)
+	if err != nil {
+		return fmt.Errorf("failed to get latest oracle checkpoint: %w", err)
+	}
+	if cp.Round != round {
+		return fmt.Errorf("oracle checkpoint round mismatch (expected: %d got: %d)", round, cp.Round)
+	}
+
+	blk, err := ctrl.RuntimeClient.GetBlock(ctx, &runtimeClient.GetBlockRequest{
+		RuntimeID: runtimeID,
+		Round:     round,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get block %d: %w", round, err)
+	}
+	var storageFound, ioFound bool
+	for _, root := range blk.Header.StorageRoots() {
+		if root.Hash.Equal(&cp.StorageRoot) {
+			storageFound = true
+		}
+		if root.Hash.Equal(&cp.IORoot) {
+			ioFound = true
+		}
+	}
+	if !storageFound || !ioFound {
+		return fmt.Errorf("oracle checkpoint roots not found among block %d storage roots", round)
+	}
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,271 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/oasislabs/ekiden/go/common/cbor"
+	"github.com/oasislabs/ekiden/go/common/crypto/hash"
+	"github.com/oasislabs/ekiden/go/storage/mkvs/urkel/node"
+)
+
+// BlobStore persists large leaf values outside of the urkel node graph, keyed by the content hash
+// of the value, so that GetNode/GetSubtree stay cheap even for keys mapped to multi-MB values.
+// Callers fetch the actual bytes on demand once they hold the ExternalValue descriptor left behind
+// in a leaf's Value field.
+type BlobStore interface {
+	// Put stores data under h, which must be the content hash of data.
+	Put(ctx context.Context, h hash.Hash, data []byte) error
+	// Get retrieves the data previously stored under h.
+	Get(ctx context.Context, h hash.Hash) ([]byte, error)
+	// Has reports whether data is stored under h.
+	Has(ctx context.Context, h hash.Hash) (bool, error)
+}
+
+// BlobOptions configures leaf value externalization, in the same style as PathOptions.
+type BlobOptions struct {
+	// Threshold is the value size in bytes above which a leaf's value is externalized to a
+	// BlobStore rather than carried inline.
+	Threshold uint64 `codec:"threshold"`
+}
+
+// DefaultBlobOptions is a conservative BlobOptions that keeps values up to 1 MiB inline. Nothing in
+// this package applies it implicitly; callers pass it explicitly.
+var DefaultBlobOptions = BlobOptions{
+	Threshold: 1 * 1024 * 1024,
+}
+
+// externalValueMagic distinguishes an externalized descriptor from a leaf value that would
+// otherwise happen to decode as one, since a value below the threshold is never rewritten.
+var externalValueMagic = [4]byte{'u', 'r', 'k', 'x'}
+
+// ExternalValue is the compact descriptor a leaf's Value field carries once its real value has
+// been externalized to a BlobStore: just enough to fetch and verify the real bytes on demand. The
+// leaf's own hash still commits to the full value, since it is computed before externalization.
+type ExternalValue struct {
+	Magic [4]byte   `codec:"magic"`
+	Hash  hash.Hash `codec:"hash"`
+	Size  uint64    `codec:"size"`
+}
+
+// ExternalizeLeafValue rewrites leaf's value in place to an ExternalValue descriptor and stores
+// the original bytes in store, if the value is larger than opts.Threshold. It is a no-op for
+// smaller values. The leaf's hash is unaffected, since hashLeaf commits to the pre-externalization
+// value.
+func ExternalizeLeafValue(ctx context.Context, store BlobStore, opts BlobOptions, leaf *node.LeafNode) error {
+	value := []byte(leaf.Value)
+	if uint64(len(value)) <= opts.Threshold {
+		return nil
+	}
+
+	var h hash.Hash
+	h.FromBytes(value)
+
+	if err := store.Put(ctx, h, value); err != nil {
+		return fmt.Errorf("urkel: failed to externalize leaf value: %w", err)
+	}
+
+	leaf.Value = cbor.Marshal(&ExternalValue{
+		Magic: externalValueMagic,
+		Hash:  h,
+		Size:  uint64(len(value)),
+	})
+	return nil
+}
+
+// ResolveLeafValue returns the real value for a leaf previously rewritten by
+// ExternalizeLeafValue, fetching it from store. If the leaf was never externalized, it returns
+// leaf.Value unchanged.
+func ResolveLeafValue(ctx context.Context, store BlobStore, leaf *node.LeafNode) ([]byte, error) {
+	var ext ExternalValue
+	if err := cbor.Unmarshal(leaf.Value, &ext); err != nil || ext.Magic != externalValueMagic {
+		return leaf.Value, nil
+	}
+
+	value, err := store.Get(ctx, ext.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("urkel: failed to resolve externalized leaf value: %w", err)
+	}
+	if uint64(len(value)) != ext.Size {
+		return nil, fmt.Errorf("urkel: externalized leaf value size mismatch (expected: %d got: %d)", ext.Size, len(value))
+	}
+	return value, nil
+}
+
+// nopBlobStore is a BlobStore that stores nothing and reports every value as missing, for use
+// when leaf externalization is disabled.
+type nopBlobStore struct{}
+
+// NewNopBlobStore creates a BlobStore that stores nothing.
+func NewNopBlobStore() BlobStore {
+	return &nopBlobStore{}
+}
+
+func (n *nopBlobStore) Put(ctx context.Context, h hash.Hash, data []byte) error {
+	return nil
+}
+
+func (n *nopBlobStore) Get(ctx context.Context, h hash.Hash) ([]byte, error) {
+	return nil, ErrNodeNotFound
+}
+
+func (n *nopBlobStore) Has(ctx context.Context, h hash.Hash) (bool, error) {
+	return false, nil
+}
+
+// memoryBlobStore is an in-memory BlobStore, useful for tests and for a syncer embedded in a
+// single process.
+type memoryBlobStore struct {
+	l    sync.Mutex
+	data map[hash.Hash][]byte
+}
+
+// NewMemoryBlobStore creates an in-memory BlobStore.
+func NewMemoryBlobStore() BlobStore {
+	return &memoryBlobStore{
+		data: make(map[hash.Hash][]byte),
+	}
+}
+
+func (m *memoryBlobStore) Put(ctx context.Context, h hash.Hash, data []byte) error {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	cp := make([]byte, len(data))
+	copy(cp, data)
+	m.data[h] = cp
+	return nil
+}
+
+func (m *memoryBlobStore) Get(ctx context.Context, h hash.Hash) ([]byte, error) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	data, ok := m.data[h]
+	if !ok {
+		return nil, ErrNodeNotFound
+	}
+	return data, nil
+}
+
+func (m *memoryBlobStore) Has(ctx context.Context, h hash.Hash) (bool, error) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	_, ok := m.data[h]
+	return ok, nil
+}
+
+// fsBlobStore is a BlobStore backed by a content-addressed directory tree on the local
+// filesystem, one file per hash.
+type fsBlobStore struct {
+	dir string
+}
+
+// NewFSBlobStore creates a BlobStore rooted at dir, which is created if it does not already
+// exist.
+func NewFSBlobStore(dir string) (BlobStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("urkel: failed to create blob store directory: %w", err)
+	}
+	return &fsBlobStore{dir: dir}, nil
+}
+
+func (f *fsBlobStore) path(h hash.Hash) string {
+	hex := h.String()
+	return filepath.Join(f.dir, hex[:2], hex)
+}
+
+func (f *fsBlobStore) Put(ctx context.Context, h hash.Hash, data []byte) error {
+	path := f.path(h)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("urkel: failed to create blob store directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("urkel: failed to write blob: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("urkel: failed to finalize blob: %w", err)
+	}
+	return nil
+}
+
+func (f *fsBlobStore) Get(ctx context.Context, h hash.Hash) ([]byte, error) {
+	data, err := os.ReadFile(f.path(h))
+	switch {
+	case err == nil:
+		return data, nil
+	case os.IsNotExist(err):
+		return nil, ErrNodeNotFound
+	default:
+		return nil, fmt.Errorf("urkel: failed to read blob: %w", err)
+	}
+}
+
+func (f *fsBlobStore) Has(ctx context.Context, h hash.Hash) (bool, error) {
+	_, err := os.Stat(f.path(h))
+	switch {
+	case err == nil:
+		return true, nil
+	case os.IsNotExist(err):
+		return false, nil
+	default:
+		return false, fmt.Errorf("urkel: failed to stat blob: %w", err)
+	}
+}
+
+// s3Client is the subset of the S3 API used by s3BlobStore, kept as a small interface so the
+// store can be exercised without a real bucket.
+type s3Client interface {
+	PutObject(ctx context.Context, bucket, key string, data []byte) error
+	GetObject(ctx context.Context, bucket, key string) ([]byte, error)
+	HeadObject(ctx context.Context, bucket, key string) (bool, error)
+}
+
+// s3BlobStore is a BlobStore backed by an S3-compatible object store, one object per hash.
+type s3BlobStore struct {
+	client s3Client
+	bucket string
+	prefix string
+}
+
+// NewS3BlobStore creates a BlobStore backed by client, storing objects in bucket under prefix.
+func NewS3BlobStore(client s3Client, bucket, prefix string) BlobStore {
+	return &s3BlobStore{
+		client: client,
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+func (s *s3BlobStore) key(h hash.Hash) string {
+	return s.prefix + h.String()
+}
+
+func (s *s3BlobStore) Put(ctx context.Context, h hash.Hash, data []byte) error {
+	if err := s.client.PutObject(ctx, s.bucket, s.key(h), data); err != nil {
+		return fmt.Errorf("urkel: failed to put blob: %w", err)
+	}
+	return nil
+}
+
+func (s *s3BlobStore) Get(ctx context.Context, h hash.Hash) ([]byte, error) {
+	data, err := s.client.GetObject(ctx, s.bucket, s.key(h))
+	if err != nil {
+		return nil, fmt.Errorf("urkel: failed to get blob: %w", err)
+	}
+	return data, nil
+}
+
+func (s *s3BlobStore) Has(ctx context.Context, h hash.Hash) (bool, error) {
+	ok, err := s.client.HeadObject(ctx, s.bucket, s.key(h))
+	if err != nil {
+		return false, fmt.Errorf("urkel: failed to head blob: %w", err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,42 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasislabs/ekiden/go/storage/mkvs/urkel/node"
+)
+
+func TestExternalizeLeafValueRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryBlobStore()
+	opts := BlobOptions{Threshold: 4}
+
+	leaf := &node.LeafNode{Value: []byte("this value is definitely over the threshold")}
+	original := append([]byte{}, leaf.Value...)
+
+	require.NoError(t, ExternalizeLeafValue(ctx, store, opts, leaf))
+	require.NotEqual(t, original, leaf.Value, "value should have been rewritten to an ExternalValue descriptor")
+
+	resolved, err := ResolveLeafValue(ctx, store, leaf)
+	require.NoError(t, err)
+	require.Equal(t, original, resolved, "resolved value should match the original pre-externalization value")
+}
+
+func TestExternalizeLeafValueBelowThresholdIsNoOp(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryBlobStore()
+	opts := BlobOptions{Threshold: 1024}
+
+	leaf := &node.LeafNode{Value: []byte("small value")}
+	original := append([]byte{}, leaf.Value...)
+
+	require.NoError(t, ExternalizeLeafValue(ctx, store, opts, leaf))
+	require.Equal(t, original, leaf.Value, "a value under the threshold should be left inline")
+
+	resolved, err := ResolveLeafValue(ctx, store, leaf)
+	require.NoError(t, err)
+	require.Equal(t, original, resolved)
+}
@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	pbSyncer "github.com/oasislabs/ekiden/go/grpc/syncer"
+	"github.com/oasislabs/ekiden/go/storage/mkvs/urkel/node"
+	"github.com/oasislabs/ekiden/go/storage/mkvs/urkel/syncer"
+)
+
+var _ syncer.ReadSyncer = (*grpcReadSyncer)(nil)
+
+// grpcReadSyncer is a syncer.ReadSyncer that forwards every request to a remote node over gRPC.
+type grpcReadSyncer struct {
+	client pbSyncer.ReadSyncerClient
+
+	blobStore syncer.BlobStore
+}
+
+// NewReadSyncer creates a syncer.ReadSyncer that services requests against the ReadSyncer
+// exposed by cc, e.g. one dialed via Dial. Leaf values the server externalized to blobStore are
+// resolved back to their real value before a GetNode response is returned.
+func NewReadSyncer(cc *grpc.ClientConn, blobStore syncer.BlobStore) syncer.ReadSyncer {
+	return &grpcReadSyncer{
+		client:    pbSyncer.NewReadSyncerClient(cc),
+		blobStore: blobStore,
+	}
+}
+
+// Dial establishes a connection to a remote ReadSyncer at target, authenticated via creds (e.g.
+// credentials.NewTLS for a TLS-secured endpoint, or insecure.NewCredentials() for a trusted local
+// link), and returns a ReadSyncer backed by it. The caller owns the returned connection's lifetime
+// and should Close it once done.
+func Dial(target string, creds credentials.TransportCredentials, blobStore syncer.BlobStore) (syncer.ReadSyncer, *grpc.ClientConn, error) {
+	cc, err := grpc.Dial(target, grpc.WithTransportCredentials(creds)) // nolint: staticcheck
+	if err != nil {
+		return nil, nil, fmt.Errorf("syncer/grpc: failed to dial %s: %w", target, err)
+	}
+	return NewReadSyncer(cc, blobStore), cc, nil
+}
+
+func (rs *grpcReadSyncer) GetSubtree(ctx context.Context, root node.Root, id node.ID, maxDepth node.Depth) (*syncer.Subtree, error) {
+	rootBinary, err := root.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	idBinary, err := id.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := rs.client.GetSubtree(ctx, &pbSyncer.GetSubtreeRequest{
+		Root:     rootBinary,
+		Id:       idBinary,
+		MaxDepth: uint32(maxDepth),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := recvChunks(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	var subtree syncer.Subtree
+	if err := subtree.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("syncer/grpc: failed to decode subtree: %w", err)
+	}
+	return &subtree, nil
+}
+
+func (rs *grpcReadSyncer) GetPath(ctx context.Context, root node.Root, key node.Key, startBitDepth node.Depth) (*syncer.Subtree, error) {
+	rootBinary, err := root.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	keyBinary, err := key.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := rs.client.GetPath(ctx, &pbSyncer.GetPathRequest{
+		Root:          rootBinary,
+		Key:           keyBinary,
+		StartBitDepth: uint32(startBitDepth),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := recvChunks(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	var subtree syncer.Subtree
+	if err := subtree.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("syncer/grpc: failed to decode subtree: %w", err)
+	}
+	return &subtree, nil
+}
+
+func (rs *grpcReadSyncer) GetNode(ctx context.Context, root node.Root, id node.ID) (node.Node, error) {
+	rootBinary, err := root.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	idBinary, err := id.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := rs.client.GetNode(ctx, &pbSyncer.GetNodeRequest{
+		Root: rootBinary,
+		Id:   idBinary,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nd, err := node.UnmarshalBinary(rsp.GetNode())
+	if err != nil {
+		return nil, err
+	}
+
+	if leaf, ok := nd.(*node.LeafNode); ok {
+		value, err := syncer.ResolveLeafValue(ctx, rs.blobStore, leaf)
+		if err != nil {
+			return nil, err
+		}
+		leaf.Value = value
+	}
+
+	return nd, nil
+}
+
+func (rs *grpcReadSyncer) GetProof(ctx context.Context, root node.Root, key node.Key) (*syncer.Proof, error) {
+	rootBinary, err := root.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	keyBinary, err := key.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := rs.client.GetProof(ctx, &pbSyncer.GetProofRequest{
+		Root: rootBinary,
+		Key:  keyBinary,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var proof syncer.Proof
+	if err := proof.UnmarshalBinary(rsp.GetProof()); err != nil {
+		return nil, fmt.Errorf("syncer/grpc: failed to decode proof: %w", err)
+	}
+	return &proof, nil
+}
+
+// recvChunks drains a streamed GetSubtree/GetPath response into a single byte slice, propagating
+// context cancellation the same way the other streamed RPCs in this module do.
+func recvChunks(stream interface {
+	Recv() (*pbSyncer.GetSubtreeChunk, error)
+}) ([]byte, error) {
+	var data []byte
+	for {
+		chunk, err := stream.Recv()
+		switch {
+		case errors.Is(err, io.EOF):
+			return data, nil
+		case err != nil:
+			return nil, fmt.Errorf("syncer/grpc: failed to receive subtree chunk: %w", err)
+		}
+		data = append(data, chunk.GetData()...)
+	}
+}
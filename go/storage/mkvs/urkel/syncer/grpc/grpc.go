@@ -0,0 +1,160 @@
+// Package grpc exposes the syncer.ReadSyncer interface over gRPC, so that an untrusted follower
+// can hydrate its urkel cache from a remote node without embedding the storage backend, the way
+// go/client/grpc.go exposes the runtime client interface.
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	pbSyncer "github.com/oasislabs/ekiden/go/grpc/syncer"
+	"github.com/oasislabs/ekiden/go/storage/mkvs/urkel/node"
+	"github.com/oasislabs/ekiden/go/storage/mkvs/urkel/syncer"
+)
+
+// subtreeChunkSize is the maximum number of subtree bytes sent per stream message, so that large
+// subtrees don't blow gRPC's default request/response size limits.
+const subtreeChunkSize = 1 * 1024 * 1024
+
+var _ pbSyncer.ReadSyncerServer = (*grpcServer)(nil)
+
+type grpcServer struct {
+	syncer syncer.ReadSyncer
+
+	blobStore syncer.BlobStore
+	blobOpts  syncer.BlobOptions
+}
+
+func (s *grpcServer) GetSubtree(req *pbSyncer.GetSubtreeRequest, stream pbSyncer.ReadSyncer_GetSubtreeServer) error {
+	var root node.Root
+	if err := root.UnmarshalBinary(req.GetRoot()); err != nil {
+		return err
+	}
+	var id node.ID
+	if err := id.UnmarshalBinary(req.GetId()); err != nil {
+		return err
+	}
+
+	subtree, err := s.syncer.GetSubtree(stream.Context(), root, id, node.Depth(req.GetMaxDepth()))
+	if err != nil {
+		return err
+	}
+
+	data, err := subtree.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	return streamChunks(data, func(chunk []byte) error {
+		return stream.Send(&pbSyncer.GetSubtreeChunk{Data: chunk})
+	})
+}
+
+func (s *grpcServer) GetPath(req *pbSyncer.GetPathRequest, stream pbSyncer.ReadSyncer_GetPathServer) error {
+	var root node.Root
+	if err := root.UnmarshalBinary(req.GetRoot()); err != nil {
+		return err
+	}
+	var key node.Key
+	if err := key.UnmarshalBinary(req.GetKey()); err != nil {
+		return err
+	}
+
+	subtree, err := s.syncer.GetPath(stream.Context(), root, key, node.Depth(req.GetStartBitDepth()))
+	if err != nil {
+		return err
+	}
+
+	data, err := subtree.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	return streamChunks(data, func(chunk []byte) error {
+		return stream.Send(&pbSyncer.GetSubtreeChunk{Data: chunk})
+	})
+}
+
+func (s *grpcServer) GetNode(ctx context.Context, req *pbSyncer.GetNodeRequest) (*pbSyncer.GetNodeResponse, error) {
+	var root node.Root
+	if err := root.UnmarshalBinary(req.GetRoot()); err != nil {
+		return nil, err
+	}
+	var id node.ID
+	if err := id.UnmarshalBinary(req.GetId()); err != nil {
+		return nil, err
+	}
+
+	nd, err := s.syncer.GetNode(ctx, root, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if leaf, ok := nd.(*node.LeafNode); ok {
+		if err := syncer.ExternalizeLeafValue(ctx, s.blobStore, s.blobOpts, leaf); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := nd.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbSyncer.GetNodeResponse{Node: data}, nil
+}
+
+func (s *grpcServer) GetProof(ctx context.Context, req *pbSyncer.GetProofRequest) (*pbSyncer.GetProofResponse, error) {
+	var root node.Root
+	if err := root.UnmarshalBinary(req.GetRoot()); err != nil {
+		return nil, err
+	}
+	var key node.Key
+	if err := key.UnmarshalBinary(req.GetKey()); err != nil {
+		return nil, err
+	}
+
+	proof, err := s.syncer.GetProof(ctx, root, key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := proof.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbSyncer.GetProofResponse{Proof: data}, nil
+}
+
+// streamChunks splits data into subtreeChunkSize pieces and sends each with send, so callers don't
+// need to duplicate the chunking loop for every streamed RPC.
+func streamChunks(data []byte, send func([]byte) error) error {
+	if len(data) == 0 {
+		return send(nil)
+	}
+	for len(data) > 0 {
+		n := subtreeChunkSize
+		if n > len(data) {
+			n = len(data)
+		}
+		if err := send(data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// NewGRPCServer creates and registers a new gRPC server for the ReadSyncer interface. Leaf values
+// fetched via GetNode that exceed blobOpts.Threshold are externalized to blobStore before being
+// sent over the wire, so that a single large value doesn't blow up every GetNode response size.
+func NewGRPCServer(srv *grpc.Server, rs syncer.ReadSyncer, blobStore syncer.BlobStore, blobOpts syncer.BlobOptions) {
+	s := &grpcServer{
+		syncer:    rs,
+		blobStore: blobStore,
+		blobOpts:  blobOpts,
+	}
+	pbSyncer.RegisterReadSyncerServer(srv, s)
+}
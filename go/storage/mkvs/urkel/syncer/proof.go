@@ -0,0 +1,159 @@
+package syncer
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/oasislabs/ekiden/go/common/cbor"
+	"github.com/oasislabs/ekiden/go/common/crypto/hash"
+	"github.com/oasislabs/ekiden/go/storage/mkvs/urkel/node"
+)
+
+// ProofStep is one step along the root-to-leaf path, carrying what is needed to reconstruct a
+// parent node's hash from one of its children.
+type ProofStep struct {
+	// LabelBits is the compressed edge label consumed by this internal node, one byte per bit
+	// (zero for an unset bit, non-zero for a set bit), most-significant-bit-first, matching
+	// node.Key.GetBit. VerifyProof checks these against the queried key's (and, for an absence
+	// proof, the proof leaf's) own bits, so a step's label can't be swapped for an unrelated one.
+	LabelBits []byte `codec:"label_bits"`
+	// Sibling is the hash of the child the path does not continue into.
+	Sibling hash.Hash `codec:"sibling"`
+	// Right is true if the path continues into the right child (so Sibling is the left child's
+	// hash), false if it continues into the left child.
+	Right bool `codec:"right"`
+}
+
+// ProofLeaf is the terminal leaf of a Proof: either the leaf holding the searched-for key
+// (inclusion), or a different leaf occupying the key's would-be slot (absence).
+type ProofLeaf struct {
+	Key   node.Key `codec:"key"`
+	Value []byte   `codec:"value,omitempty"`
+}
+
+// Proof is a compact proof of inclusion or absence for a single key under a given root: the
+// ordered list of sibling hashes (and consumed label bits) along the root-to-leaf path, plus
+// enough of the terminal node to recompute its hash. A holder of only the root hash can verify it
+// with VerifyProof, without any access to the tree itself.
+//
+// For an absence proof where the search terminates in a genuinely empty subtree rather than a
+// diverging leaf, Leaf is nil.
+type Proof struct {
+	Steps []ProofStep `codec:"steps"`
+	Leaf  *ProofLeaf  `codec:"leaf,omitempty"`
+}
+
+// MarshalBinary encodes the proof for transport, independent of any Subtree snapshot.
+func (p *Proof) MarshalBinary() ([]byte, error) {
+	return cbor.Marshal(p), nil
+}
+
+// UnmarshalBinary decodes a proof previously produced by MarshalBinary.
+func (p *Proof) UnmarshalBinary(data []byte) error {
+	return cbor.Unmarshal(data, p)
+}
+
+// hashLeaf computes the hash of a leaf node holding key/value, matching the leaf hashing used by
+// the rest of the urkel node graph.
+func hashLeaf(key node.Key, value []byte) hash.Hash {
+	var h hash.Hash
+	h.FromBytes([]byte{0x00}, []byte(key), value)
+	return h
+}
+
+// hashInternal computes an internal node's hash from its consumed label bits and its two
+// children's hashes, matching the urkel node hashing used elsewhere in the module.
+func hashInternal(labelBits []byte, left, right hash.Hash) hash.Hash {
+	var h hash.Hash
+	h.FromBytes([]byte{0x01}, labelBits, left[:], right[:])
+	return h
+}
+
+// verifyKeyFollowsSteps checks that k's own bits agree, bit for bit, with the compressed edge
+// labels recorded in steps — i.e. that k would genuinely be routed down this exact root-to-leaf
+// path, not just that some (label, sibling) pair happens to hash up to the right root. steps is
+// ordered leaf-to-root (the step closest to the leaf first), so it is walked in reverse to recover
+// root-to-leaf bit order. It returns the total number of bits consumed.
+func verifyKeyFollowsSteps(steps []ProofStep, k node.Key) (node.Depth, error) {
+	var depth node.Depth
+	for i := len(steps) - 1; i >= 0; i-- {
+		for _, bit := range steps[i].LabelBits {
+			if depth >= k.BitLength() {
+				return 0, fmt.Errorf("urkel: proof path is longer than key %x", []byte(k))
+			}
+			if k.GetBit(depth) != (bit != 0) {
+				return 0, fmt.Errorf("urkel: proof path diverges from key %x before reaching the terminal node", []byte(k))
+			}
+			depth++
+		}
+	}
+	return depth, nil
+}
+
+// verifyDivergingLeaf checks that an absence proof terminating in leafKey is reached by walking
+// both key's and leafKey's bits down the same steps, and that the two keys actually diverge at the
+// very next bit past that shared path. Without this, a malicious syncer could reuse a genuine
+// proof for some unrelated leaf elsewhere in the tree to "prove" the absence of a key that is
+// actually present.
+func verifyDivergingLeaf(steps []ProofStep, key, leafKey node.Key) error {
+	depth, err := verifyKeyFollowsSteps(steps, key)
+	if err != nil {
+		return err
+	}
+	if _, err := verifyKeyFollowsSteps(steps, leafKey); err != nil {
+		return fmt.Errorf("urkel: proof leaf key does not follow the proof path: %w", err)
+	}
+
+	if depth >= key.BitLength() || depth >= leafKey.BitLength() {
+		return fmt.Errorf("urkel: proof leaf does not diverge from the queried key")
+	}
+	if key.GetBit(depth) == leafKey.GetBit(depth) {
+		return fmt.Errorf("urkel: proof leaf does not diverge from the queried key at the expected bit")
+	}
+	return nil
+}
+
+// VerifyProof checks that proof is a valid proof, under root, that key maps to value (inclusion,
+// value non-nil) or that key is absent from the tree (absence, value nil). It requires no access
+// to the tree: only the root hash, the searched key/value, and the proof itself.
+func VerifyProof(root hash.Hash, key node.Key, value []byte, proof *Proof) error {
+	var current hash.Hash
+	switch {
+	case proof.Leaf == nil:
+		if value != nil {
+			return fmt.Errorf("urkel: proof of absence given for a non-nil expected value")
+		}
+		if _, err := verifyKeyFollowsSteps(proof.Steps, key); err != nil {
+			return err
+		}
+		current.Empty()
+	case bytes.Equal(proof.Leaf.Key, key):
+		if !bytes.Equal(proof.Leaf.Value, value) {
+			return fmt.Errorf("urkel: proof leaf value does not match expected value")
+		}
+		current = hashLeaf(proof.Leaf.Key, proof.Leaf.Value)
+	default:
+		// A different leaf occupies the key's would-be slot: this is only a valid absence proof,
+		// and only if that leaf is genuinely the one the tree would route key to.
+		if value != nil {
+			return fmt.Errorf("urkel: proof leaf key does not match expected key")
+		}
+		if err := verifyDivergingLeaf(proof.Steps, key, proof.Leaf.Key); err != nil {
+			return err
+		}
+		current = hashLeaf(proof.Leaf.Key, proof.Leaf.Value)
+	}
+
+	for _, step := range proof.Steps {
+		if step.Right {
+			current = hashInternal(step.LabelBits, step.Sibling, current)
+		} else {
+			current = hashInternal(step.LabelBits, current, step.Sibling)
+		}
+	}
+
+	if !current.Equal(&root) {
+		return fmt.Errorf("urkel: proof does not verify against root")
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasislabs/ekiden/go/common/crypto/hash"
+	"github.com/oasislabs/ekiden/go/storage/mkvs/urkel/node"
+)
+
+func TestVerifyProofInclusion(t *testing.T) {
+	key := node.Key("test-key")
+	value := []byte("test-value")
+	labelBits := []byte{0xab}
+
+	var sibling hash.Hash
+	sibling.FromBytes([]byte("sibling"))
+
+	root := hashInternal(labelBits, sibling, hashLeaf(key, value))
+	proof := &Proof{
+		Leaf: &ProofLeaf{Key: key, Value: value},
+		Steps: []ProofStep{
+			{LabelBits: labelBits, Sibling: sibling, Right: true},
+		},
+	}
+
+	require.NoError(t, VerifyProof(root, key, value, proof))
+}
+
+func TestVerifyProofAbsenceEmptySubtree(t *testing.T) {
+	// key = 0xA5 = 0b10100101: its first three bits are 1, 0, 1, matching labelBits below.
+	key := node.Key([]byte{0xA5})
+	labelBits := []byte{1, 0, 1}
+
+	var sibling hash.Hash
+	sibling.FromBytes([]byte("sibling"))
+	var empty hash.Hash
+	empty.Empty()
+
+	root := hashInternal(labelBits, sibling, empty)
+	proof := &Proof{
+		Leaf: nil,
+		Steps: []ProofStep{
+			{LabelBits: labelBits, Sibling: sibling, Right: true},
+		},
+	}
+
+	require.NoError(t, VerifyProof(root, key, nil, proof))
+}
+
+func TestVerifyProofAbsenceDivergingLeaf(t *testing.T) {
+	// key = 0xA0 = 0b10100000 and leafKey = 0xB0 = 0b10110000 share the first three bits (1, 0,
+	// 1, matching labelBits below) and diverge at the fourth (key: 0, leafKey: 1).
+	key := node.Key([]byte{0xA0})
+	leafKey := node.Key([]byte{0xB0})
+	leafValue := []byte("other-value")
+	labelBits := []byte{1, 0, 1}
+
+	var sibling hash.Hash
+	sibling.FromBytes([]byte("sibling"))
+
+	root := hashInternal(labelBits, sibling, hashLeaf(leafKey, leafValue))
+	proof := &Proof{
+		Leaf: &ProofLeaf{Key: leafKey, Value: leafValue},
+		Steps: []ProofStep{
+			{LabelBits: labelBits, Sibling: sibling, Right: true},
+		},
+	}
+
+	require.NoError(t, VerifyProof(root, key, nil, proof))
+}
+
+func TestVerifyProofRejectsUnrelatedKeyReuse(t *testing.T) {
+	// A genuine absence proof for 0xA0 (see TestVerifyProofAbsenceDivergingLeaf) must not also
+	// verify as an absence proof for some other key whose bits have nothing to do with the path
+	// this proof actually walks, even though the proof still hashes up to the correct root.
+	leafKey := node.Key([]byte{0xB0})
+	leafValue := []byte("other-value")
+	labelBits := []byte{1, 0, 1}
+
+	var sibling hash.Hash
+	sibling.FromBytes([]byte("sibling"))
+
+	root := hashInternal(labelBits, sibling, hashLeaf(leafKey, leafValue))
+	proof := &Proof{
+		Leaf: &ProofLeaf{Key: leafKey, Value: leafValue},
+		Steps: []ProofStep{
+			{LabelBits: labelBits, Sibling: sibling, Right: true},
+		},
+	}
+
+	// unrelatedKey = 0x40 = 0b01000000: its first bit (0) already disagrees with labelBits[0]
+	// (1), so it was never on this path at all.
+	unrelatedKey := node.Key([]byte{0x40})
+	require.Error(t, VerifyProof(root, unrelatedKey, nil, proof), "a proof of one key's absence must not verify for an unrelated key reusing the same steps")
+}
+
+func TestVerifyProofRejectsTamperedProof(t *testing.T) {
+	key := node.Key("test-key")
+	value := []byte("test-value")
+	labelBits := []byte{0xab}
+
+	var sibling hash.Hash
+	sibling.FromBytes([]byte("sibling"))
+
+	root := hashInternal(labelBits, sibling, hashLeaf(key, value))
+	proof := &Proof{
+		Leaf: &ProofLeaf{Key: key, Value: []byte("tampered-value")},
+		Steps: []ProofStep{
+			{LabelBits: labelBits, Sibling: sibling, Right: true},
+		},
+	}
+
+	require.Error(t, VerifyProof(root, key, value, proof), "a proof whose leaf was tampered with must not verify")
+}
@@ -0,0 +1,24 @@
+package syncer
+
+import (
+	"github.com/oasislabs/ekiden/go/common/crypto/hash"
+)
+
+// Digest returns a deterministic content hash over the subtree's canonical binary encoding
+// (MarshalBinary), used by VerifiedCache to detect a stale or corrupted cache entry without
+// needing to compare against the tree itself. Two subtrees that encode identically always have
+// the same digest, and conversely a changed digest always means the encoding changed.
+func (s *Subtree) Digest() hash.Hash {
+	var h hash.Hash
+
+	data, err := s.MarshalBinary()
+	if err != nil {
+		// MarshalBinary only fails on a malformed in-memory subtree, which should never happen
+		// for anything fetched over GetSubtree/GetPath. Return the zero digest rather than
+		// panicking; two subtrees that both fail to encode therefore share the same digest.
+		return h
+	}
+
+	h.FromBytes(data)
+	return h
+}
@@ -9,10 +9,11 @@ import (
 )
 
 var (
-	ErrDirtyRoot    = errors.New("urkel: root is dirty")
-	ErrInvalidRoot  = errors.New("urkel: invalid root")
-	ErrNodeNotFound = errors.New("urkel: node not found during sync")
-	ErrUnsupported  = errors.New("urkel: method not supported")
+	ErrDirtyRoot      = errors.New("urkel: root is dirty")
+	ErrInvalidRoot    = errors.New("urkel: invalid root")
+	ErrNodeNotFound   = errors.New("urkel: node not found during sync")
+	ErrUnsupported    = errors.New("urkel: method not supported")
+	ErrCacheCorrupted = errors.New("urkel: cached subtree digest mismatch")
 )
 
 // PathOptions are the options for GetPath queries.
@@ -46,6 +47,10 @@ type ReadSyncer interface {
 	// is consistent. The node's cached hash should be considered invalid
 	// and must be recomputed locally.
 	GetNode(ctx context.Context, root node.Root, id node.ID) (node.Node, error)
+
+	// GetProof retrieves a compact proof of inclusion or absence for the given key under the
+	// given root, verifiable via VerifyProof by a caller that holds only the root hash.
+	GetProof(ctx context.Context, root node.Root, key node.Key) (*Proof, error)
 }
 
 // nopReadSyncer is a no-op read syncer.
@@ -67,3 +72,7 @@ func (r *nopReadSyncer) GetPath(ctx context.Context, root node.Root, key node.Ke
 func (r *nopReadSyncer) GetNode(ctx context.Context, root node.Root, id node.ID) (node.Node, error) {
 	return nil, ErrNodeNotFound
 }
+
+func (r *nopReadSyncer) GetProof(ctx context.Context, root node.Root, key node.Key) (*Proof, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,209 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/oasislabs/ekiden/go/common/cbor"
+	"github.com/oasislabs/ekiden/go/common/crypto/hash"
+	"github.com/oasislabs/ekiden/go/storage/mkvs/urkel/node"
+)
+
+var _ ReadSyncer = (*VerifiedCache)(nil)
+
+// cacheKeyKind distinguishes a GetSubtree cache entry from a GetPath one, since GetSubtree's id
+// and GetPath's key both default to their zero value and would otherwise collide (e.g.
+// GetSubtree(root, node.ID{}, maxDepth) and GetPath(root, node.Key{}, maxDepth) would produce an
+// identical key without this field).
+type cacheKeyKind int
+
+const (
+	cacheKeyKindSubtree cacheKeyKind = iota
+	cacheKeyKindPath
+)
+
+// cacheKey identifies a single GetSubtree/GetPath response. id is populated for a GetSubtree
+// entry; key is populated for a GetPath entry; maxDepth holds maxDepth or startBitDepth
+// respectively. kind disambiguates which of the two a given entry is for.
+type cacheKey struct {
+	kind     cacheKeyKind
+	root     hash.Hash
+	id       node.ID
+	key      string
+	maxDepth node.Depth
+}
+
+// cacheEntry is a cached subtree together with the digest it was stored under.
+type cacheEntry struct {
+	subtree *Subtree
+	digest  hash.Hash
+}
+
+// VerifiedCache wraps a ReadSyncer with a digest-verified cache of GetSubtree/GetPath responses.
+// A cache hit whose recomputed Subtree.Digest still matches what was stored skips the remote
+// round trip entirely. A hit whose digest no longer matches is treated as corrupted: the entry is
+// evicted and ErrCacheCorrupted is returned rather than silently serving bad data. GetNode and
+// GetProof are passed straight through, since they are cheap enough on their own not to need
+// caching.
+type VerifiedCache struct {
+	syncer ReadSyncer
+
+	l       sync.Mutex
+	entries map[cacheKey]*cacheEntry
+}
+
+// NewVerifiedCache creates a VerifiedCache wrapping syncer.
+func NewVerifiedCache(syncer ReadSyncer) *VerifiedCache {
+	return &VerifiedCache{
+		syncer:  syncer,
+		entries: make(map[cacheKey]*cacheEntry),
+	}
+}
+
+func (c *VerifiedCache) GetSubtree(ctx context.Context, root node.Root, id node.ID, maxDepth node.Depth) (*Subtree, error) {
+	key := cacheKey{kind: cacheKeyKindSubtree, root: root.Hash, id: id, maxDepth: maxDepth}
+	if subtree, err := c.lookup(key); subtree != nil || err != nil {
+		return subtree, err
+	}
+
+	subtree, err := c.syncer.GetSubtree(ctx, root, id, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, subtree)
+	return subtree, nil
+}
+
+func (c *VerifiedCache) GetPath(ctx context.Context, root node.Root, key node.Key, startBitDepth node.Depth) (*Subtree, error) {
+	ck := cacheKey{kind: cacheKeyKindPath, root: root.Hash, key: string(key), maxDepth: startBitDepth}
+	if subtree, err := c.lookup(ck); subtree != nil || err != nil {
+		return subtree, err
+	}
+
+	subtree, err := c.syncer.GetPath(ctx, root, key, startBitDepth)
+	if err != nil {
+		return nil, err
+	}
+	c.store(ck, subtree)
+	return subtree, nil
+}
+
+func (c *VerifiedCache) GetNode(ctx context.Context, root node.Root, id node.ID) (node.Node, error) {
+	return c.syncer.GetNode(ctx, root, id)
+}
+
+func (c *VerifiedCache) GetProof(ctx context.Context, root node.Root, key node.Key) (*Proof, error) {
+	return c.syncer.GetProof(ctx, root, key)
+}
+
+// lookup returns a verified cache hit, (nil, nil) on a plain miss, or (nil, ErrCacheCorrupted) if
+// a cached entry's digest no longer matches its recomputed value, after evicting it.
+func (c *VerifiedCache) lookup(key cacheKey) (*Subtree, error) {
+	c.l.Lock()
+	entry, ok := c.entries[key]
+	c.l.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	digest := entry.subtree.Digest()
+	if !digest.Equal(&entry.digest) {
+		c.l.Lock()
+		delete(c.entries, key)
+		c.l.Unlock()
+		return nil, ErrCacheCorrupted
+	}
+	return entry.subtree, nil
+}
+
+func (c *VerifiedCache) store(key cacheKey, subtree *Subtree) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.entries[key] = &cacheEntry{
+		subtree: subtree,
+		digest:  subtree.Digest(),
+	}
+}
+
+// cacheFileEntry is the on-disk representation of a single VerifiedCache entry, used by
+// MarshalBinary/UnmarshalBinary to persist the cache across restarts.
+type cacheFileEntry struct {
+	Kind     cacheKeyKind `codec:"kind"`
+	Root     hash.Hash    `codec:"root"`
+	ID       node.ID      `codec:"id"`
+	Key      string       `codec:"key,omitempty"`
+	MaxDepth node.Depth   `codec:"max_depth"`
+	Subtree  []byte       `codec:"subtree"`
+	Digest   hash.Hash    `codec:"digest"`
+}
+
+// MarshalBinary encodes the full contents of the cache for persistence across restarts. Entries
+// are sorted by digest before encoding so that two processes holding the same cache contents
+// produce byte-identical output, regardless of Go's randomized map iteration order.
+func (c *VerifiedCache) MarshalBinary() ([]byte, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	fileEntries := make([]cacheFileEntry, 0, len(c.entries))
+	for key, entry := range c.entries {
+		data, err := entry.subtree.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("urkel: failed to encode cached subtree: %w", err)
+		}
+		fileEntries = append(fileEntries, cacheFileEntry{
+			Kind:     key.kind,
+			Root:     key.root,
+			ID:       key.id,
+			Key:      key.key,
+			MaxDepth: key.maxDepth,
+			Subtree:  data,
+			Digest:   entry.digest,
+		})
+	}
+	sort.Slice(fileEntries, func(i, j int) bool {
+		di, dj := fileEntries[i].Digest.String(), fileEntries[j].Digest.String()
+		if di != dj {
+			return di < dj
+		}
+		// Identical subtrees cached under different keys share a digest; break the tie on
+		// the entry's full encoding so the order stays total.
+		return string(cbor.Marshal(fileEntries[i])) < string(cbor.Marshal(fileEntries[j]))
+	})
+
+	return cbor.Marshal(fileEntries), nil
+}
+
+// UnmarshalBinary replaces the cache's contents with what was encoded by a prior MarshalBinary
+// call. An entry whose subtree fails to decode, or whose recomputed digest no longer matches the
+// recorded one, is dropped rather than failing the whole restore.
+func (c *VerifiedCache) UnmarshalBinary(data []byte) error {
+	var fileEntries []cacheFileEntry
+	if err := cbor.Unmarshal(data, &fileEntries); err != nil {
+		return fmt.Errorf("urkel: failed to decode cache: %w", err)
+	}
+
+	restored := make(map[cacheKey]*cacheEntry, len(fileEntries))
+	for _, fe := range fileEntries {
+		var subtree Subtree
+		if err := subtree.UnmarshalBinary(fe.Subtree); err != nil {
+			continue
+		}
+		digest := subtree.Digest()
+		if !digest.Equal(&fe.Digest) {
+			continue
+		}
+
+		restored[cacheKey{kind: fe.Kind, root: fe.Root, id: fe.ID, key: fe.Key, maxDepth: fe.MaxDepth}] = &cacheEntry{
+			subtree: &subtree,
+			digest:  digest,
+		}
+	}
+
+	c.l.Lock()
+	c.entries = restored
+	c.l.Unlock()
+	return nil
+}
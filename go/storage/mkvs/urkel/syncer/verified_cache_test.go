@@ -0,0 +1,21 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasislabs/ekiden/go/storage/mkvs/urkel/node"
+)
+
+func TestCacheKeyDoesNotCollideAcrossKinds(t *testing.T) {
+	var root node.Root
+
+	// An ordinary GetSubtree(root, node.ID{}, maxDepth) call and a GetPath(root, node.Key{},
+	// maxDepth) call both leave id/key at their zero value, so kind is the only thing that can
+	// tell the two apart.
+	subtreeKey := cacheKey{kind: cacheKeyKindSubtree, root: root.Hash, id: node.ID{}, maxDepth: 3}
+	pathKey := cacheKey{kind: cacheKeyKindPath, root: root.Hash, key: string(node.Key{}), maxDepth: 3}
+
+	require.NotEqual(t, subtreeKey, pathKey, "a GetSubtree entry must not collide with a GetPath entry for the same root/depth")
+}
@@ -0,0 +1,62 @@
+package keymanager
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519/extra/ecvrf"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/keymanager/api"
+)
+
+func TestVerifyBlockHeightProof(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	var vrfPub signature.PublicKey
+	copy(vrfPub[:], pub)
+
+	var runtimeID common.Namespace
+	epoch := beacon.EpochTime(10)
+	beaconRandomness := []byte("beacon randomness for epoch 10")
+
+	proof, err := ecvrf.Prove(priv, vrfAlpha(beaconRandomness, runtimeID, epoch))
+	require.NoError(t, err)
+
+	height, err := VerifyBlockHeightProof(vrfPub, beaconRandomness, runtimeID, epoch, 100, 50, proof)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, height, int64(100))
+	require.Less(t, height, int64(150))
+
+	otherProof, err := ecvrf.Prove(priv, vrfAlpha(beaconRandomness, runtimeID, epoch+1))
+	require.NoError(t, err)
+	_, err = VerifyBlockHeightProof(vrfPub, beaconRandomness, runtimeID, epoch, 100, 50, otherProof)
+	require.Error(t, err, "a proof computed for a different epoch should not verify")
+
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	var otherVRFPub signature.PublicKey
+	copy(otherVRFPub[:], otherPub)
+	_, err = VerifyBlockHeightProof(otherVRFPub, beaconRandomness, runtimeID, epoch, 100, 50, proof)
+	require.Error(t, err, "a proof should not verify against an unrelated VRF public key")
+}
+
+func TestVerifyMasterSecretHeightProofRejectsMissingProof(t *testing.T) {
+	w := &Worker{}
+
+	err := w.verifyMasterSecretHeightProof(&api.EncryptedMasterSecret{}, &api.Status{})
+	require.Error(t, err, "a master secret with no height proof must be rejected outright, not treated as legacy data")
+}
+
+func TestVerifyMasterSecretHeightProofRejectsUnknownStatus(t *testing.T) {
+	w := &Worker{}
+
+	secret := &api.EncryptedMasterSecret{HeightProof: []byte("proof")}
+	err := w.verifyMasterSecretHeightProof(secret, nil)
+	require.Error(t, err, "a master secret cannot be verified before the key manager committee status is known")
+}
@@ -0,0 +1,42 @@
+package keymanager
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	policyCacheHitCount = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "oasis_keymanager_policy_cache_hits",
+		Help: "Number of times a sealed policy/ephemeral secret cache was successfully restored on startup.",
+	})
+
+	policyCacheMissCount = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "oasis_keymanager_policy_cache_misses",
+		Help: "Number of times no usable sealed policy/ephemeral secret cache was found on startup.",
+	})
+
+	encryptCallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "oasis_keymanager_encrypt_calls_total",
+		Help: "Number of encrypt RPC calls served by the key manager, by client runtime.",
+	}, []string{"runtime"})
+
+	decryptCallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "oasis_keymanager_decrypt_calls_total",
+		Help: "Number of decrypt RPC calls served by the key manager, by client runtime.",
+	}, []string{"runtime"})
+
+	gsmSecretVersion = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "oasis_keymanager_secrets_gsm_version",
+		Help: "Epoch of the most recently stored Google-Secret-Manager-backed master secret backup, by secret name.",
+	}, []string{"secret"})
+
+	clientRuntimeAccessListAppliedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "oasis_keymanager_access_list_applied_total",
+		Help: "Number of times a client runtime's access list was recomputed and applied, by runtime.",
+	}, []string{"runtime"})
+	clientRuntimeAccessListSuppressedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "oasis_keymanager_access_list_suppressed_total",
+		Help: "Number of times a client runtime's access list update was suppressed because the node set was unchanged, by runtime.",
+	}, []string{"runtime"})
+)
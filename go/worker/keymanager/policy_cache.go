@@ -0,0 +1,91 @@
+package keymanager
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/runtime/localstorage"
+)
+
+// policyCacheFilename is the name of the file in the node's data directory used to persist the
+// sealed policy cache.
+const policyCacheFilename = "worker-keymanager-policy-cache.db"
+
+// PolicyCache persists the sealed policy/ephemeral-secret state blob produced by the key manager
+// enclave (via api.RPCMethodSealState) across worker restarts, so that api.RPCMethodRestoreState
+// can pre-load the enclave with its last known policy and recent ephemeral secrets before
+// consensus sync and fetchLastEphemeralSecret have finished. The blob itself is opaque to the
+// worker: it is sealed and unsealed entirely by the enclave, so a PolicyCache implementation need
+// not provide any confidentiality of its own.
+type PolicyCache interface {
+	// Load returns the last sealed state blob persisted for the given runtime, or nil if none
+	// was previously saved.
+	Load(runtimeID common.Namespace) ([]byte, error)
+
+	// Save persists the sealed state blob for the given runtime, replacing any previous one.
+	Save(runtimeID common.Namespace, sealed []byte) error
+}
+
+// memoryPolicyCache is a PolicyCache backed by an in-memory map. It provides no persistence
+// across restarts. getPolicyCache falls back to it when the on-disk cache cannot be opened, and
+// tests use it to exercise the seal/restore path without touching disk.
+type memoryPolicyCache struct {
+	l     sync.Mutex
+	state map[common.Namespace][]byte
+}
+
+func newMemoryPolicyCache() *memoryPolicyCache {
+	return &memoryPolicyCache{
+		state: make(map[common.Namespace][]byte),
+	}
+}
+
+func (c *memoryPolicyCache) Load(runtimeID common.Namespace) ([]byte, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return c.state[runtimeID], nil
+}
+
+func (c *memoryPolicyCache) Save(runtimeID common.Namespace, sealed []byte) error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.state[runtimeID] = sealed
+	return nil
+}
+
+// filePolicyCache is a PolicyCache backed by the runtime-local KV store in the node's data
+// directory. This is the production implementation used by Worker.
+type filePolicyCache struct {
+	store localstorage.LocalStorage
+}
+
+func newFilePolicyCache(dataDir string) (*filePolicyCache, error) {
+	store, err := localstorage.New(filepath.Join(dataDir, policyCacheFilename))
+	if err != nil {
+		return nil, fmt.Errorf("worker/keymanager: failed to open policy cache: %w", err)
+	}
+	return &filePolicyCache{store: store}, nil
+}
+
+func (c *filePolicyCache) Load(runtimeID common.Namespace) ([]byte, error) {
+	sealed, err := c.store.Get(policyCacheKey(runtimeID))
+	if err != nil {
+		return nil, fmt.Errorf("worker/keymanager: failed to read policy cache: %w", err)
+	}
+	return sealed, nil
+}
+
+func (c *filePolicyCache) Save(runtimeID common.Namespace, sealed []byte) error {
+	if err := c.store.Set(policyCacheKey(runtimeID), sealed); err != nil {
+		return fmt.Errorf("worker/keymanager: failed to write policy cache: %w", err)
+	}
+	return nil
+}
+
+func policyCacheKey(runtimeID common.Namespace) []byte {
+	return []byte("sealed-state/" + runtimeID.String())
+}
@@ -0,0 +1,282 @@
+package keymanager
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+)
+
+// masterSecretSinkDirName is the subdirectory of the node's data directory used by the default,
+// on-disk SecretSink.
+const masterSecretSinkDirName = "worker-keymanager-secret-sink"
+
+// SecretSink mirrors successfully replicated master secrets to an operator-managed location,
+// independent of the on-chain master secret history, so that a node can be reseeded from backup
+// after losing local and on-chain state (e.g. a fresh node bootstrapped from a snapshot that
+// predates the relevant generations). By default the worker opens an on-disk sink under its data
+// directory (see getSecretSink).
+type SecretSink interface {
+	// StoreMasterSecret mirrors the signed, REK-encrypted master secret ciphertext and its
+	// checksum for the given epoch to the sink. It is called each time the worker has itself
+	// successfully replicated (loaded) a master secret, so the same epoch may be stored again.
+	StoreMasterSecret(ctx context.Context, epoch beacon.EpochTime, ciphertext, checksum []byte) error
+
+	// LoadMasterSecret returns the ciphertext previously stored for the given epoch, or nil if
+	// the sink has nothing for that epoch.
+	LoadMasterSecret(ctx context.Context, epoch beacon.EpochTime) ([]byte, error)
+}
+
+// fsSecretSink is a SecretSink backed by plain files in a directory, one pair of
+// "<epoch>.secret"/"<epoch>.sha256" files per epoch. Writes are atomic (write to a temporary file,
+// then rename) so a crash mid-write can never leave a corrupt secret behind.
+type fsSecretSink struct {
+	dir string
+}
+
+func newFSSecretSink(dir string) (*fsSecretSink, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("worker/keymanager: failed to create secret sink directory: %w", err)
+	}
+	return &fsSecretSink{dir: dir}, nil
+}
+
+func (s *fsSecretSink) secretPath(epoch beacon.EpochTime) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%d.secret", epoch))
+}
+
+func (s *fsSecretSink) checksumPath(epoch beacon.EpochTime) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%d.sha256", epoch))
+}
+
+func (s *fsSecretSink) StoreMasterSecret(_ context.Context, epoch beacon.EpochTime, ciphertext, checksum []byte) error {
+	if err := atomicWriteFile(s.secretPath(epoch), ciphertext); err != nil {
+		return fmt.Errorf("worker/keymanager: failed to store master secret: %w", err)
+	}
+	if err := atomicWriteFile(s.checksumPath(epoch), checksum); err != nil {
+		return fmt.Errorf("worker/keymanager: failed to store master secret checksum: %w", err)
+	}
+	return nil
+}
+
+func (s *fsSecretSink) LoadMasterSecret(_ context.Context, epoch beacon.EpochTime) ([]byte, error) {
+	ciphertext, err := os.ReadFile(s.secretPath(epoch))
+	switch {
+	case err == nil:
+	case os.IsNotExist(err):
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("worker/keymanager: failed to read master secret: %w", err)
+	}
+
+	checksum, err := os.ReadFile(s.checksumPath(epoch))
+	if err != nil {
+		return nil, fmt.Errorf("worker/keymanager: failed to read master secret checksum: %w", err)
+	}
+	sum := sha256.Sum256(ciphertext)
+	if !bytes.Equal(sum[:], checksum) {
+		return nil, fmt.Errorf("worker/keymanager: master secret checksum mismatch for epoch %d", epoch)
+	}
+
+	return ciphertext, nil
+}
+
+func atomicWriteFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+const (
+	gsmRefreshInterval = 5 * time.Minute
+	gsmRefreshJitter   = 30 * time.Second
+)
+
+// gsmClient is the subset of the Google Secret Manager API used by gsmSecretSink, kept as a small
+// interface so the sink can be exercised without a real GCP project.
+type gsmClient interface {
+	// AddSecretVersion adds data as a new, immutable version of the named secret and returns the
+	// new version's ID.
+	AddSecretVersion(ctx context.Context, secretName string, data []byte) (version string, err error)
+	// AccessSecretVersion returns the payload of the given version of the named secret.
+	AccessSecretVersion(ctx context.Context, secretName, version string) ([]byte, error)
+	// ListSecretVersions returns the IDs of all enabled versions of the named secret, oldest first.
+	ListSecretVersions(ctx context.Context, secretName string) ([]string, error)
+	// DestroySecretVersion permanently disables and removes the payload of the given version.
+	DestroySecretVersion(ctx context.Context, secretName, version string) error
+}
+
+// gsmSecretSink is a SecretSink backed by a single named Google Secret Manager secret, where each
+// epoch's master secret is stored as a new version rather than overwriting the secret in place --
+// matching how Secret Manager is meant to be used for rotated material. Versions beyond retention
+// are destroyed so the secret doesn't grow without bound.
+type gsmSecretSink struct {
+	client     gsmClient
+	secretName string
+	retention  int
+
+	l               sync.Mutex
+	versionForEpoch map[beacon.EpochTime]string
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// gsmSecretEnvelope is the actual payload stored as a Secret Manager version: the epoch alongside
+// the ciphertext. Secret Manager versions carry no metadata of their own, so without this the
+// sink would have no way to rebuild versionForEpoch from ListSecretVersions' opaque version IDs
+// after a restart.
+type gsmSecretEnvelope struct {
+	Epoch      beacon.EpochTime `json:"epoch"`
+	Ciphertext []byte           `json:"ciphertext"`
+}
+
+// newGSMSecretSink creates a gsmSecretSink backed by client, storing versions of the secret named
+// secretName and keeping at most retention of the newest versions. It synchronously rebuilds
+// versionForEpoch from whatever is already in the backend before returning, so that a freshly
+// started node can reseed from it immediately rather than waiting for the first periodic refresh.
+func newGSMSecretSink(client gsmClient, secretName string, retention int) *gsmSecretSink {
+	s := &gsmSecretSink{
+		client:          client,
+		secretName:      secretName,
+		retention:       retention,
+		versionForEpoch: make(map[beacon.EpochTime]string),
+		stopCh:          make(chan struct{}),
+	}
+	s.refresh()
+	go s.refreshLoop()
+	return s
+}
+
+func (s *gsmSecretSink) StoreMasterSecret(ctx context.Context, epoch beacon.EpochTime, ciphertext, _ []byte) error {
+	payload := cbor.Marshal(&gsmSecretEnvelope{Epoch: epoch, Ciphertext: ciphertext})
+	version, err := s.client.AddSecretVersion(ctx, s.secretName, payload)
+	if err != nil {
+		return fmt.Errorf("worker/keymanager: failed to add secret version: %w", err)
+	}
+
+	s.l.Lock()
+	s.versionForEpoch[epoch] = version
+	s.l.Unlock()
+
+	gsmSecretVersion.WithLabelValues(s.secretName).Set(float64(epoch))
+
+	go s.pruneOldVersions(context.Background())
+
+	return nil
+}
+
+func (s *gsmSecretSink) LoadMasterSecret(ctx context.Context, epoch beacon.EpochTime) ([]byte, error) {
+	s.l.Lock()
+	version, cached := s.versionForEpoch[epoch]
+	s.l.Unlock()
+	if !cached {
+		return nil, nil
+	}
+
+	payload, err := s.client.AccessSecretVersion(ctx, s.secretName, version)
+	if err != nil {
+		return nil, fmt.Errorf("worker/keymanager: failed to access secret version: %w", err)
+	}
+
+	var envelope gsmSecretEnvelope
+	if err := cbor.Unmarshal(payload, &envelope); err != nil {
+		return nil, fmt.Errorf("worker/keymanager: failed to decode secret version envelope: %w", err)
+	}
+	return envelope.Ciphertext, nil
+}
+
+// pruneOldVersions destroys the oldest tracked versions once more than retention are known,
+// freeing operators from having to manage version cleanup by hand.
+func (s *gsmSecretSink) pruneOldVersions(ctx context.Context) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if len(s.versionForEpoch) <= s.retention {
+		return
+	}
+
+	epochs := make([]beacon.EpochTime, 0, len(s.versionForEpoch))
+	for epoch := range s.versionForEpoch {
+		epochs = append(epochs, epoch)
+	}
+	sortEpochs(epochs)
+
+	for _, epoch := range epochs[:len(epochs)-s.retention] {
+		version := s.versionForEpoch[epoch]
+		if err := s.client.DestroySecretVersion(ctx, s.secretName, version); err != nil {
+			continue
+		}
+		delete(s.versionForEpoch, epoch)
+	}
+}
+
+func sortEpochs(epochs []beacon.EpochTime) {
+	for i := 1; i < len(epochs); i++ {
+		for j := i; j > 0 && epochs[j-1] > epochs[j]; j-- {
+			epochs[j-1], epochs[j] = epochs[j], epochs[j-1]
+		}
+	}
+}
+
+// refreshLoop periodically re-lists the secret's versions from the backend on a jittered timer,
+// so the in-memory version cache reflects out-of-band changes (e.g. manual cleanup in the GCP
+// console) without operators needing to restart the node.
+func (s *gsmSecretSink) refreshLoop() {
+	for {
+		jitter := time.Duration(rand.Int63n(int64(gsmRefreshJitter))) // nolint: gosec
+		select {
+		case <-time.After(gsmRefreshInterval + jitter):
+			s.refresh()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// refresh rebuilds versionForEpoch from scratch by listing every version currently in the backend
+// and decoding each one's envelope to recover its epoch. ListSecretVersions returns only opaque
+// version IDs with no epoch metadata, so a version's epoch can only be recovered by reading its
+// payload; pruning stale entries out of the existing map is not enough, since on first call (e.g.
+// right after a restart) that map starts out empty.
+func (s *gsmSecretSink) refresh() {
+	ctx := context.Background()
+	versions, err := s.client.ListSecretVersions(ctx, s.secretName)
+	if err != nil {
+		return
+	}
+
+	rebuilt := make(map[beacon.EpochTime]string, len(versions))
+	for _, version := range versions {
+		payload, err := s.client.AccessSecretVersion(ctx, s.secretName, version)
+		if err != nil {
+			// The version may have been destroyed out-of-band between listing and fetching it;
+			// skip it rather than fail the whole refresh.
+			continue
+		}
+		var envelope gsmSecretEnvelope
+		if err := cbor.Unmarshal(payload, &envelope); err != nil {
+			continue
+		}
+		rebuilt[envelope.Epoch] = version
+	}
+
+	s.l.Lock()
+	s.versionForEpoch = rebuilt
+	s.l.Unlock()
+}
+
+// Stop terminates the background refresh loop.
+func (s *gsmSecretSink) Stop() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+}
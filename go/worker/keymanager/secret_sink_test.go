@@ -0,0 +1,121 @@
+package keymanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+)
+
+func TestFSSecretSinkStoreLoadRoundTrip(t *testing.T) {
+	sink, err := newFSSecretSink(t.TempDir())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	ciphertext := []byte("super secret master secret ciphertext")
+	checksum := sha256.Sum256(ciphertext)
+
+	require.NoError(t, sink.StoreMasterSecret(ctx, beacon.EpochTime(42), ciphertext, checksum[:]))
+
+	loaded, err := sink.LoadMasterSecret(ctx, beacon.EpochTime(42))
+	require.NoError(t, err)
+	require.Equal(t, ciphertext, loaded, "loaded ciphertext should match what was stored")
+}
+
+func TestFSSecretSinkLoadUnknownEpoch(t *testing.T) {
+	sink, err := newFSSecretSink(t.TempDir())
+	require.NoError(t, err)
+
+	loaded, err := sink.LoadMasterSecret(context.Background(), beacon.EpochTime(7))
+	require.NoError(t, err)
+	require.Nil(t, loaded, "an epoch that was never stored should load as nil, not an error")
+}
+
+// fakeGSMClient is an in-memory stand-in for gsmClient, backed by a plain map of version ID to
+// payload, so gsmSecretSink can be exercised without a real GCP project.
+type fakeGSMClient struct {
+	nextVersion int
+	versions    map[string][]byte
+}
+
+func newFakeGSMClient() *fakeGSMClient {
+	return &fakeGSMClient{versions: make(map[string][]byte)}
+}
+
+func (c *fakeGSMClient) AddSecretVersion(_ context.Context, _ string, data []byte) (string, error) {
+	c.nextVersion++
+	version := fmt.Sprintf("v%d", c.nextVersion)
+	c.versions[version] = data
+	return version, nil
+}
+
+func (c *fakeGSMClient) AccessSecretVersion(_ context.Context, _, version string) ([]byte, error) {
+	data, ok := c.versions[version]
+	if !ok {
+		return nil, fmt.Errorf("fakeGSMClient: unknown version %s", version)
+	}
+	return data, nil
+}
+
+func (c *fakeGSMClient) ListSecretVersions(_ context.Context, _ string) ([]string, error) {
+	versions := make([]string, 0, len(c.versions))
+	for version := range c.versions {
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+func (c *fakeGSMClient) DestroySecretVersion(_ context.Context, _, version string) error {
+	delete(c.versions, version)
+	return nil
+}
+
+func TestGSMSecretSinkRebuildsVersionsAfterRestart(t *testing.T) {
+	client := newFakeGSMClient()
+	ctx := context.Background()
+
+	sink := newGSMSecretSink(client, "projects/test/secrets/master-secret", 10)
+	ciphertext := []byte("super secret master secret ciphertext")
+	require.NoError(t, sink.StoreMasterSecret(ctx, beacon.EpochTime(5), ciphertext, nil))
+	sink.Stop()
+
+	// A brand new sink, as created after a process restart, starts out with an empty
+	// versionForEpoch. Without decoding each listed version's envelope to recover its epoch, it
+	// would have no way to find what StoreMasterSecret above just wrote.
+	restarted := newGSMSecretSink(client, "projects/test/secrets/master-secret", 10)
+	defer restarted.Stop()
+
+	loaded, err := restarted.LoadMasterSecret(ctx, beacon.EpochTime(5))
+	require.NoError(t, err)
+	require.Equal(t, ciphertext, loaded, "a restarted sink should reseed its version map from the backend and find the stored epoch")
+}
+
+func TestGSMSecretSinkRefreshPrunesDestroyedVersions(t *testing.T) {
+	client := newFakeGSMClient()
+	ctx := context.Background()
+
+	sink := newGSMSecretSink(client, "projects/test/secrets/master-secret", 10)
+	defer sink.Stop()
+
+	require.NoError(t, sink.StoreMasterSecret(ctx, beacon.EpochTime(1), []byte("secret-1"), nil))
+	require.NoError(t, sink.StoreMasterSecret(ctx, beacon.EpochTime(2), []byte("secret-2"), nil))
+
+	sink.l.Lock()
+	version := sink.versionForEpoch[beacon.EpochTime(1)]
+	sink.l.Unlock()
+	require.NoError(t, client.DestroySecretVersion(ctx, sink.secretName, version))
+
+	sink.refresh()
+
+	loaded, err := sink.LoadMasterSecret(ctx, beacon.EpochTime(1))
+	require.NoError(t, err)
+	require.Nil(t, loaded, "a version destroyed out-of-band should be pruned by refresh")
+
+	loaded, err = sink.LoadMasterSecret(ctx, beacon.EpochTime(2))
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret-2"), loaded, "refresh must not drop versions that are still present")
+}
@@ -2,10 +2,15 @@ package keymanager
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"math"
+	"math/big"
 	"math/rand"
+	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -13,12 +18,14 @@ import (
 	"github.com/libp2p/go-libp2p/core"
 	"golang.org/x/exp/slices"
 
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519/extra/ecvrf"
 	"github.com/oasisprotocol/curve25519-voi/primitives/x25519"
 
 	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
 	"github.com/oasisprotocol/oasis-core/go/common"
 	cmnBackoff "github.com/oasisprotocol/oasis-core/go/common/backoff"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 	"github.com/oasisprotocol/oasis-core/go/common/logging"
 	"github.com/oasisprotocol/oasis-core/go/common/node"
@@ -46,6 +53,15 @@ const (
 	loadEphemeralSecretMaxRetries     = 5
 	generateEphemeralSecretMaxRetries = 5
 	ephemeralSecretCacheSize          = 20
+
+	loadMasterSecretMaxRetries     = 5
+	generateMasterSecretMaxRetries = 5
+	masterSecretCacheSize          = 20
+
+	// minProposalReplicationPercent is the minimum percentage of the current committee's REKs
+	// that a proposed master secret rotation must be encrypted to before it is published, so that
+	// enough committee enclaves are able to replicate it.
+	minProposalReplicationPercent = 66
 )
 
 var (
@@ -87,6 +103,11 @@ type Worker struct { // nolint: maligned
 	accessListByRuntime map[common.Namespace][]core.PeerID
 	privatePeers        map[core.PeerID]struct{}
 
+	// mayEncryptRuntimes and mayDecryptRuntimes are the sets of client runtime IDs whose policy
+	// grants them the MayEncrypt/MayDecrypt bit, checked in CallEnclave next to accessList.
+	mayEncryptRuntimes map[common.Namespace]struct{}
+	mayDecryptRuntimes map[common.Namespace]struct{}
+
 	commonWorker *workerCommon.Worker
 	roleProvider registration.RoleProvider
 	backend      api.Backend
@@ -96,13 +117,44 @@ type Worker struct { // nolint: maligned
 	policy         *api.SignedPolicySGX
 	policyChecksum []byte
 
-	numLoadedSecrets    int
-	lastLoadedSecret    beacon.EpochTime
-	numGeneratedSecrets int
-	lastGeneratedSecret beacon.EpochTime
+	numLoadedEphemeralSecrets    int
+	lastLoadedEphemeralSecret    beacon.EpochTime
+	numGeneratedEphemeralSecrets int
+	lastGeneratedEphemeralSecret beacon.EpochTime
+
+	numLoadedMasterSecrets         int
+	lastLoadedMasterSecret         uint64
+	lastLoadedMasterSecretEpoch    beacon.EpochTime
+	numGeneratedMasterSecrets      int
+	lastGeneratedMasterSecret      uint64
+	lastGeneratedMasterSecretEpoch beacon.EpochTime
 
 	enabled     bool
 	mayGenerate bool
+
+	// randomSource derives the block height used to stagger secret generation attempts across
+	// the committee. It is nil in production, in which case randomBlockHeight falls back to the
+	// verifiable beaconRandomnessSource; tests can set it to a legacyRandomnessSource or a mock
+	// for determinism.
+	randomSource RandomnessSource
+
+	// policyCache persists the sealed policy and recent ephemeral secrets across restarts. It is
+	// nil until getPolicyCache first opens the on-disk cache, unless a test has already set it.
+	policyCache PolicyCache
+
+	// secretSink mirrors replicated master secrets to an operator-managed backup location. It is
+	// nil until getSecretSink first opens the default on-disk sink, unless a test has already set
+	// it.
+	secretSink SecretSink
+
+	// sealStateCh is the trigger channel requestSealAndPersistState uses to ask worker()'s select
+	// loop to run sealAndPersistState, serializing calls that used to race as detached goroutines.
+	// It is nil until getSealStateCh first creates it.
+	sealStateCh chan struct{}
+
+	// pendingSealRuntimeID is the runtime ID the next sealStateCh-triggered sealAndPersistState
+	// call should use, set by requestSealAndPersistState.
+	pendingSealRuntimeID common.Namespace
 }
 
 func (w *Worker) Name() string {
@@ -176,6 +228,29 @@ func (w *Worker) CallEnclave(ctx context.Context, data []byte, kind enclaverpc.K
 			// Anyone can connect.
 		case api.RPCMethodGetPublicKey, api.RPCMethodGetPublicEphemeralKey:
 			// Anyone can get public keys.
+		case api.RPCMethodEncrypt, api.RPCMethodDecrypt:
+			if _, privatePeered := w.privatePeers[peerID]; !privatePeered {
+				// In addition to being on the access list, the runtime the caller belongs to must
+				// also carry the matching MayEncrypt/MayDecrypt policy bit. The same check is
+				// repeated inside the enclave in case the node lied about which runtime it is.
+				w.RLock()
+				allowedCapability := w.mayEncryptRuntimes
+				if frame.UntrustedPlaintext == api.RPCMethodDecrypt {
+					allowedCapability = w.mayDecryptRuntimes
+				}
+				namespaces, allowed := w.accessList[peerID]
+				runtimeID, capable := runtimeInSet(namespaces, allowedCapability)
+				w.RUnlock()
+				if !allowed || !capable {
+					return nil, fmt.Errorf("not authorized")
+				}
+
+				if frame.UntrustedPlaintext == api.RPCMethodEncrypt {
+					encryptCallsTotal.WithLabelValues(runtimeID.String()).Inc()
+				} else {
+					decryptCallsTotal.WithLabelValues(runtimeID.String()).Inc()
+				}
+			}
 		default:
 			if _, privatePeered := w.privatePeers[peerID]; !privatePeered {
 				// Defer to access control to check the policy.
@@ -377,6 +452,10 @@ func (w *Worker) updateStatus(status *api.Status, runtimeStatus *runtimeStatus)
 	w.policy = status.Policy
 	w.policyChecksum = signedInitResp.InitResponse.PolicyChecksum
 
+	// The write lock is held until return and requestSealAndPersistState takes it itself, so
+	// issue the request from its own goroutine.
+	go w.requestSealAndPersistState(w.runtime.ID())
+
 	return nil
 }
 
@@ -387,20 +466,56 @@ func (w *Worker) setStatus(status *api.Status) {
 	w.globalStatus = status
 }
 
-func (w *Worker) setLastGeneratedSecretEpoch(epoch beacon.EpochTime) {
+func (w *Worker) setLastGeneratedEphemeralSecretEpoch(epoch beacon.EpochTime) {
 	w.Lock()
 	defer w.Unlock()
 
-	w.numGeneratedSecrets++
-	w.lastGeneratedSecret = epoch
+	w.numGeneratedEphemeralSecrets++
+	w.lastGeneratedEphemeralSecret = epoch
 }
 
-func (w *Worker) setLastLoadedSecretEpoch(epoch beacon.EpochTime) {
+func (w *Worker) setLastLoadedEphemeralSecretEpoch(epoch beacon.EpochTime) {
 	w.Lock()
 	defer w.Unlock()
 
-	w.numLoadedSecrets++
-	w.lastLoadedSecret = epoch
+	w.numLoadedEphemeralSecrets++
+	w.lastLoadedEphemeralSecret = epoch
+}
+
+func (w *Worker) setLastGeneratedMasterSecretGeneration(generation uint64) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.numGeneratedMasterSecrets++
+	w.lastGeneratedMasterSecret = generation
+}
+
+func (w *Worker) setLastLoadedMasterSecretGeneration(generation uint64) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.numLoadedMasterSecrets++
+	w.lastLoadedMasterSecret = generation
+}
+
+// setLastGeneratedMasterSecretEpoch records the epoch in which a master secret proposal was
+// last generated, surfaced next to lastGeneratedMasterSecret through the worker's status so that
+// operators can observe rotation progress in terms of epochs, not just generations.
+func (w *Worker) setLastGeneratedMasterSecretEpoch(epoch beacon.EpochTime) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.lastGeneratedMasterSecretEpoch = epoch
+}
+
+// setLastLoadedMasterSecretEpoch records the epoch of the master secret that was last
+// successfully replicated into the enclave, the loading counterpart of
+// setLastGeneratedMasterSecretEpoch.
+func (w *Worker) setLastLoadedMasterSecretEpoch(epoch beacon.EpochTime) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.lastLoadedMasterSecretEpoch = epoch
 }
 
 func (w *Worker) addClientRuntimeWatcher(n common.Namespace, crw *clientRuntimeWatcher) {
@@ -468,6 +583,21 @@ func (w *Worker) startClientRuntimeWatcher(rt *registry.Runtime, status *api.Sta
 		return nil
 	}
 
+	// Check policy document for the runtime's encrypt/decrypt capability, in addition to the
+	// query capability checked above.
+	var mayEncrypt, mayDecrypt bool
+	if status.Policy != nil {
+		for _, enc := range status.Policy.Policy.Enclaves {
+			if _, ok := enc.MayEncrypt[rt.ID]; ok {
+				mayEncrypt = true
+			}
+			if _, ok := enc.MayDecrypt[rt.ID]; ok {
+				mayDecrypt = true
+			}
+		}
+	}
+	w.setRuntimeCapability(rt.ID, mayEncrypt, mayDecrypt)
+
 	nodes, err := nodes.NewVersionedNodeDescriptorWatcher(w.ctx, w.commonWorker.Consensus)
 	if err != nil {
 		w.logger.Error("unable to create new client runtime node watcher",
@@ -558,7 +688,44 @@ func (w *Worker) setAccessList(runtimeID common.Namespace, nodes []*node.Node) {
 	)
 }
 
-func (w *Worker) generateEphemeralSecret(runtimeID common.Namespace, epoch beacon.EpochTime, kmStatus *api.Status, runtimeStatus *runtimeStatus) error {
+// setRuntimeCapability records whether the given client runtime's policy grants it the
+// MayEncrypt/MayDecrypt bit, checked by CallEnclave next to the access list.
+func (w *Worker) setRuntimeCapability(runtimeID common.Namespace, mayEncrypt, mayDecrypt bool) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.mayEncryptRuntimes == nil {
+		w.mayEncryptRuntimes = make(map[common.Namespace]struct{})
+	}
+	if w.mayDecryptRuntimes == nil {
+		w.mayDecryptRuntimes = make(map[common.Namespace]struct{})
+	}
+
+	if mayEncrypt {
+		w.mayEncryptRuntimes[runtimeID] = struct{}{}
+	} else {
+		delete(w.mayEncryptRuntimes, runtimeID)
+	}
+	if mayDecrypt {
+		w.mayDecryptRuntimes[runtimeID] = struct{}{}
+	} else {
+		delete(w.mayDecryptRuntimes, runtimeID)
+	}
+}
+
+// runtimeInSet returns a runtime ID present in both namespaces and allowed, used to check whether
+// a peer's access-listed runtimes intersect with the set of runtimes permitted a capability that
+// is tracked separately from plain access-list membership (e.g. encrypt/decrypt).
+func runtimeInSet(namespaces, allowed map[common.Namespace]struct{}) (common.Namespace, bool) {
+	for ns := range namespaces {
+		if _, ok := allowed[ns]; ok {
+			return ns, true
+		}
+	}
+	return common.Namespace{}, false
+}
+
+func (w *Worker) generateEphemeralSecret(runtimeID common.Namespace, epoch beacon.EpochTime, kmStatus *api.Status, runtimeStatus *runtimeStatus, heightProof []byte) error {
 	w.logger.Info("generating ephemeral secret",
 		"epoch", epoch,
 	)
@@ -592,7 +759,8 @@ func (w *Worker) generateEphemeralSecret(runtimeID common.Namespace, epoch beaco
 
 	// Generate ephemeral secret.
 	args := api.GenerateEphemeralSecretRequest{
-		Epoch: epoch,
+		Epoch:       epoch,
+		HeightProof: heightProof,
 	}
 
 	var rsp api.GenerateEphemeralSecretResponse
@@ -682,6 +850,14 @@ func (w *Worker) generateEphemeralSecret(runtimeID common.Namespace, epoch beaco
 	return err
 }
 
+// isNewerEphemeralSecret reports whether candidate should replace pending as the single secret
+// the worker has queued to load, i.e. whether it is for a strictly newer epoch. Secrets can
+// arrive out of order (e.g. after a resubscription), so the pending slot must only ever move
+// forward.
+func isNewerEphemeralSecret(pending, candidate *api.SignedEncryptedEphemeralSecret) bool {
+	return pending == nil || candidate.Secret.Epoch > pending.Secret.Epoch
+}
+
 func (w *Worker) loadEphemeralSecret(sigSecret *api.SignedEncryptedEphemeralSecret) error {
 	w.logger.Info("loading ephemeral secret",
 		"epoch", sigSecret.Secret.Epoch,
@@ -699,11 +875,17 @@ func (w *Worker) loadEphemeralSecret(sigSecret *api.SignedEncryptedEphemeralSecr
 		return fmt.Errorf("failed to load ephemeral secret: %w", err)
 	}
 
+	w.requestSealAndPersistState(sigSecret.Secret.ID)
+
 	return nil
 }
 
-func (w *Worker) fetchLastEphemeralSecrets(runtimeID common.Namespace) ([]*api.SignedEncryptedEphemeralSecret, error) {
-	w.logger.Info("fetching last ephemeral secrets")
+// fetchLastEphemeralSecret looks back over the last ephemeralSecretCacheSize epochs for the most
+// recently published ephemeral secret, returning nil if none was found, so the worker has
+// something to load immediately after a runtime (re)start without waiting for a new one to be
+// published.
+func (w *Worker) fetchLastEphemeralSecret(runtimeID common.Namespace) (*api.SignedEncryptedEphemeralSecret, error) {
+	w.logger.Info("fetching last ephemeral secret")
 
 	// Get next epoch.
 	epoch, err := w.commonWorker.Consensus.Beacon().GetEpoch(w.ctx, consensus.HeightLatest)
@@ -715,9 +897,8 @@ func (w *Worker) fetchLastEphemeralSecrets(runtimeID common.Namespace) ([]*api.S
 	}
 	epoch++
 
-	// Fetch last few ephemeral secrets.
+	// Walk back over the last few epochs and return the newest secret found.
 	N := ephemeralSecretCacheSize
-	secrets := make([]*api.SignedEncryptedEphemeralSecret, 0, N)
 	for i := 0; i < N && epoch > 0; i, epoch = i+1, epoch-1 {
 		secret, err := w.commonWorker.Consensus.KeyManager().GetEphemeralSecret(w.ctx, &registry.NamespaceEpochQuery{
 			Height: consensus.HeightLatest,
@@ -727,7 +908,7 @@ func (w *Worker) fetchLastEphemeralSecrets(runtimeID common.Namespace) ([]*api.S
 
 		switch err {
 		case nil:
-			secrets = append(secrets, secret)
+			return secret, nil
 		case api.ErrNoSuchEphemeralSecret:
 			// Secret hasn't been published.
 		default:
@@ -738,37 +919,569 @@ func (w *Worker) fetchLastEphemeralSecrets(runtimeID common.Namespace) ([]*api.S
 		}
 	}
 
+	return nil, nil
+}
+
+// nextMasterSecretGeneration returns the generation that master secret rotation should attempt to
+// publish next, i.e. the one after the newest generation already on-chain.
+func nextMasterSecretGeneration(status *api.Status) uint64 {
+	return status.Generation + 1
+}
+
+func (w *Worker) generateMasterSecret(runtimeID common.Namespace, generation uint64, epoch beacon.EpochTime, kmStatus *api.Status, runtimeStatus *runtimeStatus, heightProof []byte) error {
+	w.logger.Info("generating master secret",
+		"generation", generation,
+	)
+
+	// Check if secret has been published. Note that despite this check, the nodes can still publish
+	// master secrets at the same time.
+	_, err := w.commonWorker.Consensus.KeyManager().GetMasterSecret(w.ctx, &api.NamespaceGenerationQuery{
+		Height:     consensus.HeightLatest,
+		ID:         runtimeID,
+		Generation: generation,
+	})
+	switch err {
+	case nil:
+		w.logger.Info("skipping secret generation, master secret already published")
+		return nil
+	case api.ErrNoSuchMasterSecret:
+		// Secret hasn't been published.
+	default:
+		w.logger.Error("failed to fetch master secret",
+			"err", err,
+		)
+		return fmt.Errorf("failed to fetch master secret: %w", err)
+	}
+
+	// Skip generation if the node is not in the key manager committee.
+	id := w.commonWorker.Identity.NodeSigner.Public()
+	if !slices.Contains(kmStatus.Nodes, id) {
+		w.logger.Info("skipping master secret generation, node not in the key manager committee")
+		return fmt.Errorf("node not in the key manager committee")
+	}
+
+	// Generate master secret.
+	args := api.GenerateMasterSecretRequest{
+		Generation:  generation,
+		Epoch:       epoch,
+		HeightProof: heightProof,
+	}
+
+	var rsp api.GenerateMasterSecretResponse
+	if err = w.localCallEnclave(api.RPCMethodGenerateMasterSecret, args, &rsp); err != nil {
+		w.logger.Error("failed to generate master secret",
+			"err", err,
+		)
+		return fmt.Errorf("failed to generate master secret: %w", err)
+	}
+
+	// Fetch key manager runtime details.
+	kmRt, err := w.commonWorker.Consensus.Registry().GetRuntime(w.ctx, &registry.GetRuntimeQuery{
+		Height: consensus.HeightLatest,
+		ID:     kmStatus.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Fetch RAK.
+	var rak signature.PublicKey
+	switch kmRt.TEEHardware {
+	case node.TEEHardwareInvalid:
+		rak = api.InsecureRAK
+	case node.TEEHardwareIntelSGX:
+		if runtimeStatus.capabilityTEE == nil {
+			return fmt.Errorf("node doesn't have TEE capability")
+		}
+		rak = runtimeStatus.capabilityTEE.RAK
+	default:
+		return fmt.Errorf("TEE hardware mismatch")
+	}
+
+	// Fetch REKs of the key manager committee.
+	reks := make(map[x25519.PublicKey]struct{})
+	for _, id := range kmStatus.Nodes {
+		var n *node.Node
+		n, err = w.commonWorker.Consensus.Registry().GetNode(w.ctx, &registry.IDQuery{
+			Height: consensus.HeightLatest,
+			ID:     id,
+		})
+		switch err {
+		case nil:
+		case registry.ErrNoSuchNode:
+			continue
+		default:
+			return err
+		}
+
+		idx := slices.IndexFunc(n.Runtimes, func(rt *node.Runtime) bool {
+			// Skipping version check as key managers are running exactly one
+			// version of the runtime.
+			return rt.ID == kmStatus.ID
+		})
+		if idx == -1 {
+			continue
+		}
+		nRt := n.Runtimes[idx]
+
+		var rek x25519.PublicKey
+		switch kmRt.TEEHardware {
+		case node.TEEHardwareInvalid:
+			rek = api.InsecureREK
+		case node.TEEHardwareIntelSGX:
+			if nRt.Capabilities.TEE == nil || nRt.Capabilities.TEE.REK == nil {
+				continue
+			}
+			rek = *nRt.Capabilities.TEE.REK
+		default:
+			continue
+		}
+
+		reks[rek] = struct{}{}
+	}
+
+	// Require the proposal to be encrypted to enough of the committee's REKs before publishing
+	// it, so that it has a realistic chance of being replicated by enough committee enclaves.
+	// The proposal is only accepted on-chain once that replication actually happens; this is just
+	// an early, local check to avoid publishing proposals that can never reach quorum.
+	if coverage := len(reks) * 100 / len(kmStatus.Nodes); coverage < minProposalReplicationPercent {
+		return fmt.Errorf("master secret proposal only covers %d%% of the committee, need at least %d%%", coverage, minProposalReplicationPercent)
+	}
+
+	// Verify the response.
+	if err = rsp.SignedSecret.Verify(epoch, reks, rak); err != nil {
+		return fmt.Errorf("failed to validate generate master secret response signature: %w", err)
+	}
+
+	// Publish transaction.
+	tx := api.NewPublishMasterSecretTx(0, nil, &rsp.SignedSecret)
+	if err = consensus.SignAndSubmitTx(w.ctx, w.commonWorker.Consensus, w.commonWorker.Identity.NodeSigner, tx); err != nil {
+		return err
+	}
+
+	return err
+}
+
+// verifyMasterSecretHeightProof checks that a received master secret's HeightProof is a valid VRF
+// proof from one of the key manager committee's nodes for the secret's runtime and epoch. Only the
+// proof itself is checked: the height at which the secret was actually published is not compared
+// with the height the proof derives.
+// generateMasterSecret passes on whatever proof randomBlockHeight produced, which is empty when
+// height selection failed or the randomness source gives none. A missing proof is rejected rather
+// than treated as a legacy secret from before height verification existed: there is no such legacy data
+// in this system, and accepting an empty proof would let a generator opt out of the very guarantee
+// this check exists to enforce.
+func (w *Worker) verifyMasterSecretHeightProof(secret *api.EncryptedMasterSecret, kmStatus *api.Status) error {
+	if len(secret.HeightProof) == 0 {
+		return fmt.Errorf("keymanager/worker: master secret is missing its height proof")
+	}
+	if kmStatus == nil {
+		return fmt.Errorf("keymanager/worker: key manager status not yet known, cannot verify height proof")
+	}
+
+	first, interval, err := w.epochBlockInterval(secret.Epoch, 90)
+	if err != nil {
+		return fmt.Errorf("keymanager/worker: failed to determine height proof interval: %w", err)
+	}
+	beaconRandomness, err := w.commonWorker.Consensus.Beacon().GetBeacon(w.ctx, first)
+	if err != nil {
+		return fmt.Errorf("keymanager/worker: failed to fetch beacon randomness: %w", err)
+	}
+
+	for _, id := range kmStatus.Nodes {
+		n, err := w.commonWorker.Consensus.Registry().GetNode(w.ctx, &registry.IDQuery{
+			Height: consensus.HeightLatest,
+			ID:     id,
+		})
+		if err != nil {
+			continue
+		}
+		if n.VRF == nil {
+			continue
+		}
+		if _, err := VerifyBlockHeightProof(n.VRF.ID, beaconRandomness, secret.ID, secret.Epoch, first, interval, secret.HeightProof); err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("keymanager/worker: master secret height proof does not verify against any committee member")
+}
+
+func (w *Worker) loadMasterSecret(sigSecret *api.SignedEncryptedMasterSecret) error {
+	w.logger.Info("loading master secret",
+		"generation", sigSecret.Secret.Generation,
+	)
+
+	args := api.LoadMasterSecretRequest{
+		SignedSecret: *sigSecret,
+	}
+
+	var rsp protocol.Empty
+	if err := w.localCallEnclave(api.RPCMethodLoadMasterSecret, args, &rsp); err != nil {
+		w.logger.Error("failed to load master secret",
+			"err", err,
+		)
+		return fmt.Errorf("failed to load master secret: %w", err)
+	}
+
+	return nil
+}
+
+func (w *Worker) fetchLastMasterSecrets(runtimeID common.Namespace, generation uint64) ([]*api.SignedEncryptedMasterSecret, error) {
+	w.logger.Info("fetching last master secrets")
+
+	// Fetch last few master secrets, working back from the current generation.
+	N := masterSecretCacheSize
+	secrets := make([]*api.SignedEncryptedMasterSecret, 0, N)
+	for i := 0; i < N; i++ {
+		secret, err := w.commonWorker.Consensus.KeyManager().GetMasterSecret(w.ctx, &api.NamespaceGenerationQuery{
+			Height:     consensus.HeightLatest,
+			ID:         runtimeID,
+			Generation: generation,
+		})
+
+		switch err {
+		case nil:
+			secrets = append(secrets, secret)
+		case api.ErrNoSuchMasterSecret:
+			// Secret hasn't been published.
+		default:
+			w.logger.Error("failed to fetch master secret",
+				"err", err,
+			)
+			return nil, fmt.Errorf("failed to fetch master secret: %w", err)
+		}
+
+		if generation == 0 {
+			break
+		}
+		generation--
+	}
+
 	return secrets, nil
 }
 
-// randomBlockHeight returns the height of a random block in the k-th percentile of the given epoch.
-func (w *Worker) randomBlockHeight(epoch beacon.EpochTime, percentile int64) (int64, error) {
-	// Get height of the first block.
+// epochBlockInterval returns the height of the first block of epoch and the number of blocks in
+// the given percentile of its interval, used to bound the search space for randomBlockHeight.
+func (w *Worker) epochBlockInterval(epoch beacon.EpochTime, percentile int64) (first, interval int64, err error) {
 	params, err := w.commonWorker.Consensus.Beacon().ConsensusParameters(w.ctx, consensus.HeightLatest)
 	if err != nil {
-		return 0, fmt.Errorf("failed to fetch consensus parameters: %w", err)
+		return 0, 0, fmt.Errorf("failed to fetch consensus parameters: %w", err)
 	}
-	first, err := w.commonWorker.Consensus.Beacon().GetEpochBlock(w.ctx, epoch)
+	first, err = w.commonWorker.Consensus.Beacon().GetEpochBlock(w.ctx, epoch)
 	if err != nil {
-		return 0, fmt.Errorf("failed to fetch epoch block height: %w", err)
+		return 0, 0, fmt.Errorf("failed to fetch epoch block height: %w", err)
 	}
 
-	// Pick a random height from the given percentile.
-	interval := params.Interval()
+	interval = params.Interval()
 	if percentile < 100 {
 		interval = interval * percentile / 100
 	}
 	if interval <= 0 {
 		interval = 1
 	}
-	height := first + rand.Int63n(interval)
+	return first, interval, nil
+}
+
+// randomBlockHeight returns the height of a pseudo-random block in the k-th percentile of the
+// given epoch, for the given runtime and committee member, along with a proof that lets other
+// committee members verify the height was not grinded by the caller. The method of derivation is
+// determined by w.randomSource, defaulting to the verifiable beaconRandomnessSource.
+func (w *Worker) randomBlockHeight(runtimeID common.Namespace, epoch beacon.EpochTime, percentile int64) (int64, []byte, error) {
+	first, interval, err := w.epochBlockInterval(epoch, percentile)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	source := w.randomSource
+	if source == nil {
+		source = &beaconRandomnessSource{w: w}
+	}
+	return source.BlockHeight(w.ctx, runtimeID, epoch, first, interval)
+}
+
+// RandomnessSource derives the pseudo-random block height at which a committee member should
+// attempt secret generation, along with a proof that lets other committee members verify that
+// the height was not chosen by grinding a local seed.
+type RandomnessSource interface {
+	// BlockHeight derives a height in [first, first+interval) for the given runtime and epoch,
+	// scoped to the caller's own identity, together with a proof of how it was derived. The
+	// proof is empty when the source does not support verification.
+	BlockHeight(ctx context.Context, runtimeID common.Namespace, epoch beacon.EpochTime, first, interval int64) (height int64, proof []byte, err error)
+}
+
+// legacyRandomnessSource picks a block height using math/rand, the way randomBlockHeight used to
+// before it became verifiable. It produces no proof, since a plaintext local seed cannot be
+// verified by peers, so it is kept only for tests that need a source they can seed
+// deterministically.
+type legacyRandomnessSource struct{}
+
+func (legacyRandomnessSource) BlockHeight(_ context.Context, _ common.Namespace, _ beacon.EpochTime, first, interval int64) (int64, []byte, error) {
+	return first + rand.Int63n(interval), nil, nil
+}
+
+// beaconRandomnessSource derives the block height from an Ed25519 VRF (RFC 9381) evaluated with
+// the node's VRF signing key over the consensus beacon randomness of the epoch's first block,
+// the runtime ID and the epoch. Because the VRF output is both unpredictable to everyone but the
+// prover and universally verifiable given the proof, no committee member can grind their way into
+// an earlier height, and peers can check that a reported height was derived honestly.
+type beaconRandomnessSource struct {
+	w *Worker
+}
+
+func (b *beaconRandomnessSource) BlockHeight(ctx context.Context, runtimeID common.Namespace, epoch beacon.EpochTime, first, interval int64) (int64, []byte, error) {
+	beaconRandomness, err := b.w.commonWorker.Consensus.Beacon().GetBeacon(ctx, first)
+	if err != nil {
+		return 0, nil, fmt.Errorf("keymanager/worker: failed to fetch beacon randomness: %w", err)
+	}
+
+	alpha := vrfAlpha(beaconRandomness, runtimeID, epoch)
+
+	proof, err := b.w.commonWorker.Identity.VRFSigner.Prove(alpha)
+	if err != nil {
+		return 0, nil, fmt.Errorf("keymanager/worker: failed to compute VRF proof: %w", err)
+	}
+	output, err := ecvrf.ProofToHash(proof)
+	if err != nil {
+		return 0, nil, fmt.Errorf("keymanager/worker: failed to derive VRF output: %w", err)
+	}
+
+	offset := new(big.Int).Mod(new(big.Int).SetBytes(output), big.NewInt(interval)).Int64()
+
+	return first + offset, proof, nil
+}
+
+// vrfAlpha constructs the VRF input used for deriving the secret generation block height, binding
+// it to the beacon randomness for the epoch, the runtime and the epoch itself so that the
+// derivation cannot be replayed across runtimes or epochs.
+func vrfAlpha(beaconRandomness []byte, runtimeID common.Namespace, epoch beacon.EpochTime) []byte {
+	return cbor.Marshal(struct {
+		Beacon    []byte           `json:"beacon"`
+		RuntimeID common.Namespace `json:"runtime_id"`
+		Epoch     beacon.EpochTime `json:"epoch"`
+	}{
+		Beacon:    beaconRandomness,
+		RuntimeID: runtimeID,
+		Epoch:     epoch,
+	})
+}
+
+// VerifyBlockHeightProof verifies that proof was produced by the node owning vrfPub over the given
+// runtime, epoch and beacon randomness, and returns the block height in [first, first+interval)
+// that the proof derives, so that the caller can compare it with a reported height.
+// It is the verification counterpart of beaconRandomnessSource.BlockHeight, used by peers that
+// receive a reported generation height together with its proof.
+func VerifyBlockHeightProof(vrfPub signature.PublicKey, beaconRandomness []byte, runtimeID common.Namespace, epoch beacon.EpochTime, first, interval int64, proof []byte) (int64, error) {
+	alpha := vrfAlpha(beaconRandomness, runtimeID, epoch)
+
+	ok, output := ecvrf.Verify(ed25519.PublicKey(vrfPub[:]), proof, alpha)
+	if !ok {
+		return 0, fmt.Errorf("keymanager/worker: invalid VRF proof")
+	}
+
+	offset := new(big.Int).Mod(new(big.Int).SetBytes(output), big.NewInt(interval)).Int64()
+
+	return first + offset, nil
+}
+
+// getPolicyCache returns the worker's PolicyCache, opening the on-disk cache on first use unless
+// a test has already injected one.
+func (w *Worker) getPolicyCache() PolicyCache {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.policyCache == nil {
+		cache, err := newFilePolicyCache(w.commonWorker.DataDir)
+		if err != nil {
+			w.logger.Error("failed to open policy cache, falling back to in-memory cache",
+				"err", err,
+			)
+			cache = nil
+		}
+		if cache != nil {
+			w.policyCache = cache
+		} else {
+			w.policyCache = newMemoryPolicyCache()
+		}
+	}
+	return w.policyCache
+}
+
+// getSecretSink returns the worker's SecretSink, opening the default on-disk sink on first use
+// unless a test has already injected one. The default sink is always available: disaster recovery
+// is opt-in by virtue of whether operators ever look at or move the backup directory, not by
+// whether a sink exists at all.
+func (w *Worker) getSecretSink() SecretSink {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.secretSink == nil {
+		sink, err := newFSSecretSink(filepath.Join(w.commonWorker.DataDir, masterSecretSinkDirName))
+		if err != nil {
+			w.logger.Error("failed to open master secret backup sink",
+				"err", err,
+			)
+			return nil
+		}
+		w.secretSink = sink
+	}
+	return w.secretSink
+}
+
+// mirrorMasterSecretToSink mirrors a successfully replicated master secret to the configured
+// backup sink. Failures are logged but otherwise ignored: the sink is a disaster-recovery
+// convenience, not a requirement for the secret to be considered loaded.
+func (w *Worker) mirrorMasterSecretToSink(sigSecret *api.SignedEncryptedMasterSecret) {
+	sink := w.getSecretSink()
+	if sink == nil {
+		return
+	}
+
+	ciphertext := cbor.Marshal(sigSecret)
+	checksum := sha256.Sum256(ciphertext)
+	if err := sink.StoreMasterSecret(w.ctx, sigSecret.Secret.Epoch, ciphertext, checksum[:]); err != nil {
+		w.logger.Error("failed to mirror master secret to backup sink",
+			"err", err,
+			"epoch", sigSecret.Secret.Epoch,
+		)
+	}
+}
+
+// reseedMasterSecretFromSink is a disaster-recovery fallback for when fetchLastMasterSecrets finds
+// nothing on-chain, e.g. a node bootstrapping from a backup rather than full consensus history. It
+// walks back over the last few epochs in the configured SecretSink, the same way
+// fetchLastEphemeralSecret walks back by epoch against the chain, and returns the newest secret
+// found for runtimeID, or nil if the sink has nothing usable.
+func (w *Worker) reseedMasterSecretFromSink(runtimeID common.Namespace) (*api.SignedEncryptedMasterSecret, error) {
+	sink := w.getSecretSink()
+	if sink == nil {
+		return nil, nil
+	}
+
+	epoch, err := w.commonWorker.Consensus.Beacon().GetEpoch(w.ctx, consensus.HeightLatest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch epoch: %w", err)
+	}
+
+	for i := 0; i < masterSecretCacheSize && epoch > 0; i, epoch = i+1, epoch-1 {
+		ciphertext, err := sink.LoadMasterSecret(w.ctx, epoch)
+		if err != nil {
+			w.logger.Error("failed to query backup sink for master secret",
+				"err", err,
+				"epoch", epoch,
+			)
+			continue
+		}
+		if ciphertext == nil {
+			continue
+		}
+
+		var sigSecret api.SignedEncryptedMasterSecret
+		if err := cbor.Unmarshal(ciphertext, &sigSecret); err != nil {
+			w.logger.Error("failed to decode master secret from backup sink",
+				"err", err,
+				"epoch", epoch,
+			)
+			continue
+		}
+		if sigSecret.Secret.ID != runtimeID {
+			continue
+		}
+
+		w.logger.Info("reseeded master secret from backup sink",
+			"epoch", epoch,
+		)
+		return &sigSecret, nil
+	}
+
+	return nil, nil
+}
+
+// restoreSealedState unseals any previously persisted policy and ephemeral secrets and pre-loads
+// them into the enclave, so that the worker can service KindNoiseSession requests for already-
+// known epochs immediately, without waiting for consensus sync, enclave init and
+// fetchLastEphemeralSecret to all complete.
+func (w *Worker) restoreSealedState(runtimeID common.Namespace) {
+	sealed, err := w.getPolicyCache().Load(runtimeID)
+	if err != nil {
+		w.logger.Error("failed to load sealed policy cache",
+			"err", err,
+		)
+		policyCacheMissCount.Inc()
+		return
+	}
+	if len(sealed) == 0 {
+		policyCacheMissCount.Inc()
+		return
+	}
+
+	args := api.RestoreStateRequest{Sealed: sealed}
+	var rsp api.RestoreStateResponse
+	if err = w.localCallEnclave(api.RPCMethodRestoreState, args, &rsp); err != nil {
+		w.logger.Error("failed to restore sealed policy cache",
+			"err", err,
+		)
+		policyCacheMissCount.Inc()
+		return
+	}
+
+	w.logger.Info("restored sealed policy and ephemeral secrets from disk")
+	policyCacheHitCount.Inc()
+}
+
+// getSealStateCh returns the worker's seal-state trigger channel, lazily creating it on first use.
+func (w *Worker) getSealStateCh() chan struct{} {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.sealStateCh == nil {
+		w.sealStateCh = make(chan struct{}, 1)
+	}
+	return w.sealStateCh
+}
+
+// requestSealAndPersistState asks worker()'s select loop to seal and persist enclave state for
+// runtimeID at its next opportunity, rather than sealing immediately in a detached goroutine.
+// Requests made while a seal is already running are coalesced into a single retrigger once it
+// finishes, so concurrent callers can never race with each other on PolicyCache.Save, and
+// worker() waits for any in-flight seal to finish before returning on shutdown.
+func (w *Worker) requestSealAndPersistState(runtimeID common.Namespace) {
+	w.Lock()
+	w.pendingSealRuntimeID = runtimeID
+	w.Unlock()
+
+	select {
+	case w.getSealStateCh() <- struct{}{}:
+	default:
+	}
+}
+
+// sealAndPersistState asks the enclave to seal its currently active policy and loaded ephemeral
+// secrets and persists the result to the policy cache, so that restoreSealedState can pre-load it
+// on the next restart.
+func (w *Worker) sealAndPersistState(runtimeID common.Namespace) {
+	var rsp api.SealStateResponse
+	if err := w.localCallEnclave(api.RPCMethodSealState, api.SealStateRequest{}, &rsp); err != nil {
+		w.logger.Error("failed to seal policy cache state",
+			"err", err,
+		)
+		return
+	}
 
-	return height, nil
+	if err := w.getPolicyCache().Save(runtimeID, rsp.Sealed); err != nil {
+		w.logger.Error("failed to persist sealed policy cache state",
+			"err", err,
+		)
+	}
 }
 
 func (w *Worker) worker() { // nolint: gocyclo
 	defer close(w.quitCh)
 
+	// Restore any previously sealed policy and ephemeral secrets before waiting for consensus
+	// sync, to shrink the cold-start window during which CallEnclave blocks on <-w.initCh.
+	w.restoreSealedState(w.runtime.ID())
+
 	// Wait for consensus sync.
 	w.logger.Info("delaying worker start until after initial synchronization")
 	select {
@@ -790,6 +1503,10 @@ func (w *Worker) worker() { // nolint: gocyclo
 	entCh, entSub := w.backend.WatchEphemeralSecrets()
 	defer entSub.Close()
 
+	// Subscribe to key manager master secret publications.
+	msCh, msSub := w.backend.WatchMasterSecrets()
+	defer msSub.Close()
+
 	// Subscribe to epoch transitions in order to know when we need to refresh
 	// the access control policy and choose a random block height for ephemeral
 	// secret generation.
@@ -831,17 +1548,41 @@ func (w *Worker) worker() { // nolint: gocyclo
 
 		epoch beacon.EpochTime
 
-		secret  *api.SignedEncryptedEphemeralSecret
-		secrets []*api.SignedEncryptedEphemeralSecret
+		secret *api.SignedEncryptedEphemeralSecret
+
+		// pendingSecret is the latest ephemeral secret known to the worker that it still needs to
+		// load into the enclave, or nil once it has been loaded (or given up on). Only the newest
+		// epoch is ever kept, as loading an older secret once a newer one is known is pointless.
+		pendingSecret *api.SignedEncryptedEphemeralSecret
+		pendingEpoch  beacon.EpochTime
 
 		loadSecretCh    = make(chan struct{}, 1)
 		loadSecretRetry = 0
 
-		genSecretCh         = make(chan struct{}, 1)
-		genSecretDoneCh     = make(chan bool, 1)
-		genSecretHeight     = int64(math.MaxInt64)
-		genSecretInProgress = false
-		genSecretRetry      = 0
+		genSecretCh          = make(chan struct{}, 1)
+		genSecretDoneCh      = make(chan bool, 1)
+		genSecretHeight      = int64(math.MaxInt64)
+		genSecretHeightProof []byte
+		genSecretInProgress  = false
+		genSecretRetry       = 0
+
+		masterSecret  *api.SignedEncryptedMasterSecret
+		masterSecrets []*api.SignedEncryptedMasterSecret
+
+		loadMasterSecretCh    = make(chan struct{}, 1)
+		loadMasterSecretRetry = 0
+
+		genMasterSecretCh          = make(chan struct{}, 1)
+		genMasterSecretDoneCh      = make(chan bool, 1)
+		genMasterSecretHeight      = int64(math.MaxInt64)
+		genMasterSecretHeightProof []byte
+		genMasterSecretInProgress  = false
+		genMasterSecretRetry       = 0
+
+		sealStateCh         = w.getSealStateCh()
+		sealStateDoneCh     = make(chan struct{}, 1)
+		sealStateInProgress = false
+		sealStatePending    = false
 
 		runtimeID = w.runtime.ID()
 	)
@@ -863,19 +1604,49 @@ func (w *Worker) worker() { // nolint: gocyclo
 					continue
 				}
 
-				// Fetch last few ephemeral secrets and send a signal to load them.
-				secrets, err = w.fetchLastEphemeralSecrets(runtimeID)
+				// Fetch the last ephemeral secret and send a signal to load it.
+				pendingSecret, err = w.fetchLastEphemeralSecret(runtimeID)
 				if err != nil {
-					w.logger.Error("failed to fetch last ephemeral secrets",
+					w.logger.Error("failed to fetch last ephemeral secret",
 						"err", err,
 					)
 				}
+				if pendingSecret != nil {
+					pendingEpoch = pendingSecret.Secret.Epoch
+				}
 				loadSecretRetry = 0
 				select {
 				case loadSecretCh <- struct{}{}:
 				default:
 				}
 
+				// Fetch last few master secrets and send a signal to load them.
+				var generation uint64
+				if currentStatus != nil {
+					generation = currentStatus.Generation
+				}
+				masterSecrets, err = w.fetchLastMasterSecrets(runtimeID, generation)
+				if err != nil {
+					w.logger.Error("failed to fetch last master secrets",
+						"err", err,
+					)
+				}
+				if len(masterSecrets) == 0 {
+					reseeded, rerr := w.reseedMasterSecretFromSink(runtimeID)
+					if rerr != nil {
+						w.logger.Error("failed to reseed master secret from backup sink",
+							"err", rerr,
+						)
+					} else if reseeded != nil {
+						masterSecrets = append(masterSecrets, reseeded)
+					}
+				}
+				loadMasterSecretRetry = 0
+				select {
+				case loadMasterSecretCh <- struct{}{}:
+				default:
+				}
+
 				if currentStatus == nil {
 					continue
 				}
@@ -1019,7 +1790,7 @@ func (w *Worker) worker() { // nolint: gocyclo
 
 			// Choose a random height for ephemeral secret generation. Avoid blocks at the end
 			// of the epoch as secret generation, publication and replication takes some time.
-			if genSecretHeight, err = w.randomBlockHeight(epoch, 90); err != nil {
+			if genSecretHeight, genSecretHeightProof, err = w.randomBlockHeight(runtimeID, epoch, 90); err != nil {
 				// If randomization fails, the height will be set to zero meaning that the ephemeral
 				// secret will be generated immediately without a delay.
 				w.logger.Error("failed to select ephemeral secret block height",
@@ -1032,6 +1803,24 @@ func (w *Worker) worker() { // nolint: gocyclo
 				"height", genSecretHeight,
 				"epoch", epoch,
 			)
+
+			// Choose a random height for master secret generation if a rotation is due in the
+			// upcoming epoch, the same way ephemeral secret generation is staggered.
+			if currentStatus != nil && currentStatus.RotationEpoch == epoch+1 {
+				if genMasterSecretHeight, genMasterSecretHeightProof, err = w.randomBlockHeight(runtimeID, epoch, 90); err != nil {
+					// If randomization fails, the height will be set to zero meaning that the
+					// master secret will be generated immediately without a delay.
+					w.logger.Error("failed to select master secret block height",
+						"err", err,
+					)
+				}
+				genMasterSecretRetry = 0
+
+				w.logger.Debug("block height for master secret generation selected",
+					"height", genMasterSecretHeight,
+					"epoch", epoch,
+				)
+			}
 		case blk, ok := <-blkCh:
 			if !ok {
 				w.logger.Error("watch blocks channel closed unexpectedly",
@@ -1048,14 +1837,31 @@ func (w *Worker) worker() { // nolint: gocyclo
 				}
 			}
 
-			// (Re)Load ephemeral secrets. When using Tendermint as a backend service the first load
-			// will probably fail as the verifier is one block behind.
-			if len(secrets) > 0 {
+			// (Re)Load the pending ephemeral secret. When using Tendermint as a backend service the
+			// first load will probably fail as the verifier is one block behind.
+			if pendingSecret != nil {
 				select {
 				case loadSecretCh <- struct{}{}:
 				default:
 				}
 			}
+
+			// (Re)Generate master secret once we reach the chosen height.
+			if blk.Height >= genMasterSecretHeight {
+				select {
+				case genMasterSecretCh <- struct{}{}:
+				default:
+				}
+			}
+
+			// (Re)Load master secrets. When using Tendermint as a backend service the first load
+			// will probably fail as the verifier is one block behind.
+			if len(masterSecrets) > 0 {
+				select {
+				case loadMasterSecretCh <- struct{}{}:
+				default:
+				}
+			}
 		case secret = <-entCh:
 			if secret.Secret.ID != runtimeID {
 				continue
@@ -1066,8 +1872,18 @@ func (w *Worker) worker() { // nolint: gocyclo
 				genSecretHeight = math.MaxInt64
 			}
 
-			// Add secret to the list and send a signal to load it.
-			secrets = append(secrets, secret)
+			if !isNewerEphemeralSecret(pendingSecret, secret) {
+				// Already have a secret for this epoch or a newer one pending; ignore.
+				w.logger.Debug("ignoring stale ephemeral secret",
+					"epoch", secret.Secret.Epoch,
+					"pending_epoch", pendingEpoch,
+				)
+				continue
+			}
+
+			// Newest epoch wins: replace whatever was pending and send a signal to load it.
+			pendingSecret = secret
+			pendingEpoch = secret.Secret.Epoch
 			loadSecretRetry = 0
 			select {
 			case loadSecretCh <- struct{}{}:
@@ -1094,8 +1910,8 @@ func (w *Worker) worker() { // nolint: gocyclo
 			genSecretInProgress = true
 
 			// Submitting transaction can take time, so don't block the loop.
-			go func(epoch beacon.EpochTime, kmStatus *api.Status, rtStatus *runtimeStatus, retry int) {
-				err2 := w.generateEphemeralSecret(runtimeID, epoch, kmStatus, rtStatus)
+			go func(epoch beacon.EpochTime, kmStatus *api.Status, rtStatus *runtimeStatus, retry int, heightProof []byte) {
+				err2 := w.generateEphemeralSecret(runtimeID, epoch, kmStatus, rtStatus, heightProof)
 				if err2 != nil {
 					w.logger.Error("failed to generate ephemeral secret",
 						"err", err2,
@@ -1105,8 +1921,8 @@ func (w *Worker) worker() { // nolint: gocyclo
 					return
 				}
 				genSecretDoneCh <- true
-				w.setLastGeneratedSecretEpoch(epoch)
-			}(epoch+1, currentStatus, currentRuntimeStatus, genSecretRetry-1)
+				w.setLastGeneratedEphemeralSecretEpoch(epoch)
+			}(epoch+1, currentStatus, currentRuntimeStatus, genSecretRetry-1, genSecretHeightProof)
 		case ok := <-genSecretDoneCh:
 			// Disarm ephemeral secret generation unless a new height was chosen.
 			if ok && genSecretRetry > 0 {
@@ -1114,24 +1930,140 @@ func (w *Worker) worker() { // nolint: gocyclo
 			}
 			genSecretInProgress = false
 		case <-loadSecretCh:
-			var failed []*api.SignedEncryptedEphemeralSecret
-			for _, secret := range secrets {
-				if err = w.loadEphemeralSecret(secret); err != nil {
-					w.logger.Error("failed to load ephemeral secret",
+			if pendingSecret == nil {
+				continue
+			}
+
+			// loadEphemeralSecret is idempotent, so simply retrying the one pending secret until
+			// it succeeds (or we give up) is sufficient; there is never more than one in flight.
+			if err = w.loadEphemeralSecret(pendingSecret); err != nil {
+				w.logger.Error("failed to load ephemeral secret",
+					"err", err,
+					"retry", loadSecretRetry,
+				)
+
+				loadSecretRetry++
+				if loadSecretRetry > loadEphemeralSecretMaxRetries {
+					// Give up on this secret.
+					pendingSecret = nil
+				}
+				continue
+			}
+
+			w.setLastLoadedEphemeralSecretEpoch(pendingSecret.Secret.Epoch)
+			pendingSecret = nil
+		case masterSecret = <-msCh:
+			if masterSecret.Secret.ID != runtimeID {
+				continue
+			}
+
+			if currentStatus != nil && masterSecret.Secret.Generation == currentStatus.Generation {
+				// Disarm master secret generation.
+				genMasterSecretHeight = math.MaxInt64
+			}
+
+			// Add secret to the list and send a signal to load it.
+			masterSecrets = append(masterSecrets, masterSecret)
+			loadMasterSecretRetry = 0
+			select {
+			case loadMasterSecretCh <- struct{}{}:
+			default:
+			}
+
+			w.logger.Debug("master secret published",
+				"generation", masterSecret.Secret.Generation,
+			)
+		case <-genMasterSecretCh:
+			if currentStatus == nil || currentRuntimeStatus == nil {
+				continue
+			}
+			if genMasterSecretInProgress || genMasterSecretHeight == math.MaxInt64 {
+				continue
+			}
+
+			genMasterSecretRetry++
+			if genMasterSecretRetry > generateMasterSecretMaxRetries {
+				// Disarm master secret generation.
+				genMasterSecretHeight = math.MaxInt64
+			}
+
+			genMasterSecretInProgress = true
+
+			// Submitting transaction can take time, so don't block the loop.
+			go func(generation uint64, epoch beacon.EpochTime, kmStatus *api.Status, rtStatus *runtimeStatus, retry int, heightProof []byte) {
+				err2 := w.generateMasterSecret(runtimeID, generation, epoch, kmStatus, rtStatus, heightProof)
+				if err2 != nil {
+					w.logger.Error("failed to generate master secret",
+						"err", err2,
+						"retry", retry,
+					)
+					genMasterSecretDoneCh <- false
+					return
+				}
+				genMasterSecretDoneCh <- true
+				w.setLastGeneratedMasterSecretGeneration(generation)
+				w.setLastGeneratedMasterSecretEpoch(epoch)
+			}(nextMasterSecretGeneration(currentStatus), epoch, currentStatus, currentRuntimeStatus, genMasterSecretRetry-1, genMasterSecretHeightProof)
+		case ok := <-genMasterSecretDoneCh:
+			// Disarm master secret generation unless a new height was chosen.
+			if ok && genMasterSecretRetry > 0 {
+				genMasterSecretHeight = math.MaxInt64
+			}
+			genMasterSecretInProgress = false
+		case <-loadMasterSecretCh:
+			var failed []*api.SignedEncryptedMasterSecret
+			for _, secret := range masterSecrets {
+				if err = w.verifyMasterSecretHeightProof(&secret.Secret, currentStatus); err != nil {
+					w.logger.Error("failed to verify master secret height proof",
+						"err", err,
+					)
+					failed = append(failed, secret)
+					continue
+				}
+				if err = w.loadMasterSecret(secret); err != nil {
+					w.logger.Error("failed to load master secret",
 						"err", err,
-						"retry", loadSecretRetry,
+						"retry", loadMasterSecretRetry,
 					)
 					failed = append(failed, secret)
 					continue
 				}
-				w.setLastLoadedSecretEpoch(secret.Secret.Epoch)
+				w.setLastLoadedMasterSecretGeneration(secret.Secret.Generation)
+				w.setLastLoadedMasterSecretEpoch(secret.Secret.Epoch)
+				go w.mirrorMasterSecretToSink(secret)
 			}
-			secrets = failed
+			masterSecrets = failed
 
-			loadSecretRetry++
-			if loadSecretRetry > loadEphemeralSecretMaxRetries {
-				// Disarm ephemeral secret loading.
-				secrets = nil
+			loadMasterSecretRetry++
+			if loadMasterSecretRetry > loadMasterSecretMaxRetries {
+				// Disarm master secret loading.
+				masterSecrets = nil
+			}
+		case <-sealStateCh:
+			if sealStateInProgress {
+				// Don't launch a second seal while one is still running; remember that another
+				// one was requested and retrigger once it finishes.
+				sealStatePending = true
+				continue
+			}
+			sealStateInProgress = true
+
+			w.RLock()
+			sealRuntimeID := w.pendingSealRuntimeID
+			w.RUnlock()
+
+			go func(id common.Namespace) {
+				w.sealAndPersistState(id)
+				sealStateDoneCh <- struct{}{}
+			}(sealRuntimeID)
+		case <-sealStateDoneCh:
+			sealStateInProgress = false
+			if sealStatePending {
+				sealStatePending = false
+				select {
+				case sealStateCh <- struct{}{}:
+				default:
+				}
 			}
 		case <-w.stopCh:
 			w.logger.Info("termination requested")
@@ -1140,16 +2072,37 @@ func (w *Worker) worker() { // nolint: gocyclo
 			if genSecretInProgress {
 				<-genSecretDoneCh
 			}
+			// Wait until master secret generation running in the background finishes.
+			if genMasterSecretInProgress {
+				<-genMasterSecretDoneCh
+			}
+			// Wait until any in-flight seal-and-persist finishes.
+			if sealStateInProgress {
+				<-sealStateDoneCh
+			}
 
 			return
 		}
 	}
 }
 
+// clientRuntimeWatcherQuietWindow is how long clientRuntimeWatcher.worker waits after the last
+// node update before recomputing and applying the access list, coalescing the burst of individual
+// node updates a committee transition produces into a single setAccessList call.
+const clientRuntimeWatcherQuietWindow = 250 * time.Millisecond
+
 type clientRuntimeWatcher struct {
 	w         *Worker
 	runtimeID common.Namespace
 	nodes     nodes.VersionedNodeDescriptorWatcher
+
+	// lastAppliedHashLock guards lastAppliedHash, since applyAccessList is called both from
+	// worker's own debounce timer and, via epochTransition, directly from Worker.worker's epoCh
+	// case running on a different goroutine.
+	lastAppliedHashLock sync.Mutex
+	// lastAppliedHash is the hash of the node set last passed to setAccessList, used to skip
+	// reapplying an access list that hasn't actually changed.
+	lastAppliedHash hash.Hash
 }
 
 func (crw *clientRuntimeWatcher) worker() {
@@ -1163,6 +2116,9 @@ func (crw *clientRuntimeWatcher) worker() {
 	}
 	defer sub.Close()
 
+	var debounce *time.Timer
+	var debounceCh <-chan time.Time
+
 	for {
 		select {
 		case <-crw.w.ctx.Done():
@@ -1174,11 +2130,54 @@ func (crw *clientRuntimeWatcher) worker() {
 				// nodes have been set (even if the new set is empty).
 				continue
 			}
-			crw.w.setAccessList(crw.runtimeID, crw.nodes.GetNodes())
+			if debounce != nil {
+				debounce.Stop()
+			}
+			debounce = time.NewTimer(clientRuntimeWatcherQuietWindow)
+			debounceCh = debounce.C
+		case <-debounceCh:
+			debounce = nil
+			debounceCh = nil
+			crw.applyAccessList()
 		}
 	}
 }
 
+// applyAccessList recomputes the access list from the current node set and applies it via
+// setAccessList only if the set differs from the one last applied, so that a burst of updates
+// that nets out to no real change doesn't ripple into a runtime host notification.
+func (crw *clientRuntimeWatcher) applyAccessList() {
+	nodes := crw.nodes.GetNodes()
+	h := hashNodeSet(nodes)
+
+	crw.lastAppliedHashLock.Lock()
+	if h.Equal(&crw.lastAppliedHash) {
+		crw.lastAppliedHashLock.Unlock()
+		clientRuntimeAccessListSuppressedCount.WithLabelValues(crw.runtimeID.String()).Inc()
+		return
+	}
+	crw.lastAppliedHash = h
+	crw.lastAppliedHashLock.Unlock()
+
+	clientRuntimeAccessListAppliedCount.WithLabelValues(crw.runtimeID.String()).Inc()
+	crw.w.setAccessList(crw.runtimeID, nodes)
+}
+
+// hashNodeSet returns a hash identifying a node set by the P2P IDs that actually drive
+// setAccessList's output, independent of watch/notification order.
+func hashNodeSet(nds []*node.Node) hash.Hash {
+	ids := make([]string, 0, len(nds))
+	for _, n := range nds {
+		ids = append(ids, n.P2P.ID.String())
+	}
+	sort.Strings(ids)
+
+	var h hash.Hash
+	hb := cbor.Marshal(ids)
+	h.FromBytes(hb)
+	return h
+}
+
 func (crw *clientRuntimeWatcher) epochTransition() {
 	crw.nodes.Reset()
 
@@ -1206,5 +2205,5 @@ func (crw *clientRuntimeWatcher) epochTransition() {
 
 	crw.nodes.Freeze(0)
 
-	crw.w.setAccessList(crw.runtimeID, crw.nodes.GetNodes())
+	crw.applyAccessList()
 }
@@ -0,0 +1,65 @@
+package keymanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/keymanager/api"
+)
+
+func secretForEpoch(epoch beacon.EpochTime) *api.SignedEncryptedEphemeralSecret {
+	return &api.SignedEncryptedEphemeralSecret{
+		Secret: api.EncryptedEphemeralSecret{
+			Epoch: epoch,
+		},
+	}
+}
+
+func TestIsNewerEphemeralSecretOutOfOrderArrival(t *testing.T) {
+	require.True(t, isNewerEphemeralSecret(nil, secretForEpoch(5)), "first secret seen should always be accepted")
+
+	pending := secretForEpoch(5)
+	require.True(t, isNewerEphemeralSecret(pending, secretForEpoch(7)), "a newer epoch should replace the pending secret")
+	require.False(t, isNewerEphemeralSecret(pending, secretForEpoch(3)), "an older, out-of-order epoch should not replace the pending secret")
+	require.False(t, isNewerEphemeralSecret(pending, secretForEpoch(5)), "a duplicate of the pending epoch should not replace it")
+}
+
+func TestNextMasterSecretGenerationAttemptsNewGeneration(t *testing.T) {
+	require.EqualValues(t, 1, nextMasterSecretGeneration(&api.Status{Generation: 0}), "rotation should attempt the generation after the one already published")
+	require.EqualValues(t, 8, nextMasterSecretGeneration(&api.Status{Generation: 7}), "rotation should never re-attempt a generation that is already published")
+}
+
+func TestRequestSealAndPersistStateCoalesces(t *testing.T) {
+	w := &Worker{}
+	ch := w.getSealStateCh()
+
+	var runtimeA, runtimeB common.Namespace
+	runtimeB[0] = 1
+
+	w.requestSealAndPersistState(runtimeA)
+	select {
+	case <-ch:
+	default:
+		t.Fatal("expected a pending seal trigger after the first request")
+	}
+
+	// A second request arriving before the first trigger is drained should only update which
+	// runtime the eventual seal uses, not queue up a second trigger alongside it.
+	w.requestSealAndPersistState(runtimeA)
+	w.requestSealAndPersistState(runtimeB)
+	select {
+	case <-ch:
+	default:
+		t.Fatal("expected a pending seal trigger after the follow-up requests")
+	}
+	select {
+	case <-ch:
+		t.Fatal("a second trigger should not be queued while one is already pending")
+	default:
+	}
+
+	require.Equal(t, runtimeB, w.pendingSealRuntimeID, "pendingSealRuntimeID should reflect the latest request")
+}